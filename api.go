@@ -0,0 +1,102 @@
+package matchers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrNoName = errors.New("matcher has no label name")
+)
+
+// APIMatcher is a matcher in the Alertmanager v2 API, such as in the matchers
+// of a silence. A matcher is one of =, !=, =~ and !~ depending on IsRegex and
+// IsEqual.
+type APIMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// UnmarshalJSON decodes the matcher like the Alertmanager API, where isEqual
+// is true if it is missing.
+func (m *APIMatcher) UnmarshalJSON(data []byte) error {
+	// Decode into a different type so this method is not called again
+	type apiMatcher APIMatcher
+	v := apiMatcher{IsEqual: true}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = APIMatcher(v)
+	return nil
+}
+
+// ParseAPIMatchers parses the input with Parse and returns the matchers in
+// the format of the Alertmanager v2 API. It returns an error if the input
+// cannot be parsed or has matchers that cannot be written in the API, such as
+// comparisons.
+func ParseAPIMatchers(input string, opts ...Option) ([]APIMatcher, error) {
+	ms, err := Parse(input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ToAPIMatchers(ms)
+}
+
+// ToAPIMatchers returns the matchers in the format of the Alertmanager v2 API.
+// It returns an error if a matcher has an unknown match type or no label name.
+func ToAPIMatchers(ms labels.Matchers) ([]APIMatcher, error) {
+	result := make([]APIMatcher, 0, len(ms))
+	for _, m := range ms {
+		if m.Name == "" {
+			return nil, fmt.Errorf("%s: %w", m, ErrNoName)
+		}
+		v := APIMatcher{Name: m.Name, Value: m.Value}
+		switch m.Type {
+		case labels.MatchEqual:
+			v.IsEqual = true
+		case labels.MatchNotEqual:
+		case labels.MatchRegexp:
+			v.IsRegex, v.IsEqual = true, true
+		case labels.MatchNotRegexp:
+			v.IsRegex = true
+		default:
+			return nil, fmt.Errorf("%s: %w", m, ErrUnsupportedMatchType)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// FromAPIMatchers returns the matchers in the format of the Alertmanager v2
+// API as labels.Matchers. It returns an error if a matcher has no label name
+// or a regex is not valid.
+func FromAPIMatchers(ms []APIMatcher) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(ms))
+	for _, m := range ms {
+		var t labels.MatchType
+		switch {
+		case !m.IsRegex && m.IsEqual:
+			t = labels.MatchEqual
+		case !m.IsRegex && !m.IsEqual:
+			t = labels.MatchNotEqual
+		case m.IsRegex && m.IsEqual:
+			t = labels.MatchRegexp
+		default:
+			t = labels.MatchNotRegexp
+		}
+		if m.Name == "" {
+			return nil, fmt.Errorf("%s%s%q: %w", m.Name, t, m.Value, ErrNoName)
+		}
+		lm, err := labels.NewMatcher(t, m.Name, m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s%s%q: %w", m.Name, t, m.Value, regexpError(m.Value, err))
+		}
+		result = append(result, lm)
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package matchers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIMatchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []APIMatcher
+		error    string
+	}{{
+		name:  "all match types",
+		input: "{a=1,b!=2,c=~\"3|4\",d!~\"5\"}",
+		expected: []APIMatcher{
+			{Name: "a", Value: "1", IsEqual: true},
+			{Name: "b", Value: "2"},
+			{Name: "c", Value: "3|4", IsRegex: true, IsEqual: true},
+			{Name: "d", Value: "5", IsRegex: true},
+		},
+	}, {
+		name:  "prefix as regex",
+		input: "{a^=\"b.c\"}",
+		expected: []APIMatcher{
+			{Name: "a", Value: "b\\.c(?s:.*)", IsRegex: true, IsEqual: true},
+		},
+	}, {
+		name:     "no matchers",
+		input:    "{}",
+		expected: []APIMatcher{},
+	}, {
+		name:  "comparison",
+		input: "{a>1}",
+		error: "a>\"1\": match type is not supported in Alertmanager",
+	}, {
+		name:  "invalid input",
+		input: "{a=}",
+		error: "1:3-1:4: unexpected }: expected label value",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms, err := ParseAPIMatchers(test.input)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, ms)
+			}
+		})
+	}
+}
+
+func TestFromAPIMatchers(t *testing.T) {
+	var ms []APIMatcher
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"name": "a", "value": "1", "isRegex": false},
+		{"name": "b", "value": "2", "isRegex": false, "isEqual": false},
+		{"name": "c", "value": "3|4", "isRegex": true, "isEqual": true},
+		{"name": "d", "value": "5", "isRegex": true, "isEqual": false}
+	]`), &ms))
+	result, err := FromAPIMatchers(ms)
+	require.NoError(t, err)
+	assert.Equal(t, labels.Matchers{
+		mustNewMatcher(t, labels.MatchEqual, "a", "1"),
+		mustNewMatcher(t, labels.MatchNotEqual, "b", "2"),
+		mustNewMatcher(t, labels.MatchRegexp, "c", "3|4"),
+		mustNewMatcher(t, labels.MatchNotRegexp, "d", "5"),
+	}, result)
+	// The matchers must convert back to the same API matchers
+	apiMatchers, err := ToAPIMatchers(result)
+	require.NoError(t, err)
+	assert.Equal(t, ms, apiMatchers)
+
+	_, err = FromAPIMatchers([]APIMatcher{{Name: "a", Value: "[a-", IsRegex: true}})
+	require.EqualError(t, err, "a!~\"[a-\": error parsing regexp: missing closing ]: `[a-`")
+	_, err = FromAPIMatchers([]APIMatcher{{Value: "a", IsEqual: true}})
+	require.EqualError(t, err, "=\"a\": matcher has no label name")
+}
+
+func TestAPIMatchers_RoundTrip(t *testing.T) {
+	// Like in Alertmanager the regex is ^(?:a)|(b)$ which is valid
+	ms, err := ParseAPIMatchers("{foo=~\"a)|(b\"}")
+	require.NoError(t, err)
+	result, err := FromAPIMatchers(ms)
+	require.NoError(t, err)
+	assert.Equal(t, labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "foo", "a)|(b")}, result)
+}
+
+func TestAPIMatcher_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal([]APIMatcher{{Name: "a", Value: "1", IsRegex: true}})
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name":"a","value":"1","isRegex":true,"isEqual":false}]`, string(b))
+}
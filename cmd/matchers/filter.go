@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grobinson-grafana/matchers"
+)
+
+// alert is the part of an alert from the Alertmanager API that is matched.
+type alert struct {
+	Labels model.LabelSet `json:"labels"`
+}
+
+// runFilter prints the alerts on stdin that match the matchers in the
+// argument. The alerts can be newline-delimited JSON or a JSON array, and the
+// matching alerts are printed as newline-delimited JSON. With --invert it
+// prints the alerts that do not match, and with --count it prints the number of
+// alerts instead. It returns errNoMatch if no alerts are printed or counted.
+func runFilter(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("filter", stdout)
+	invert := fs.Bool("invert", false, "print the alerts that do not match")
+	count := fs.Bool("count", false, "print the number of alerts instead of the alerts")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("expected matchers")
+	}
+	ms, err := matchers.ParseMatchers(args[0])
+	if err != nil {
+		return parseError{err}
+	}
+	var (
+		read int // the number of alerts read
+		n    int // the number of alerts printed or counted
+	)
+	err = readAlerts(stdin, func(raw json.RawMessage) error {
+		read++
+		var a alert
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return fmt.Errorf("alert %d: %w", read, err)
+		}
+		if ms.Matches(a.Labels) == *invert {
+			return nil
+		}
+		n++
+		if *count {
+			return nil
+		}
+		// Alerts are compacted so each alert is printed on one line
+		var b bytes.Buffer
+		if err := json.Compact(&b, raw); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+		_, err := stdout.Write(b.Bytes())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if *count {
+		if _, err = fmt.Fprintln(stdout, n); err != nil {
+			return err
+		}
+	}
+	if n == 0 {
+		return errNoMatch
+	}
+	return nil
+}
+
+// readAlerts calls fn with each alert in r, where r contains either
+// newline-delimited JSON or a JSON array.
+func readAlerts(r io.Reader, fn func(raw json.RawMessage) error) error {
+	// The first rune that is not whitespace is '[' if r is a JSON array
+	br := bufio.NewReader(r)
+	c, _, err := br.ReadRune()
+	for err == nil && unicode.IsSpace(c) {
+		c, _, err = br.ReadRune()
+	}
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err = br.UnreadRune(); err != nil {
+		return err
+	}
+	var (
+		dec     = json.NewDecoder(br)
+		isArray = c == '['
+	)
+	if isArray {
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+	for !isArray || dec.More() {
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			if !isArray && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err = fn(raw); err != nil {
+			return err
+		}
+	}
+	// The array must have its close bracket
+	if _, err = dec.Token(); errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
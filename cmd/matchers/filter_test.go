@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_Filter(t *testing.T) {
+	const (
+		ndjson = `{"labels":{"severity":"critical","team":"core"},"status":"active"}
+{"labels":{"severity":"warning"}}
+
+{"fingerprint":"abc"}
+`
+		array = `[
+  {
+    "labels": {"severity": "critical", "team": "core"},
+    "status": "active"
+  },
+  {"labels": {"severity": "warning"}}
+]`
+	)
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		code     int
+		expected string
+		error    string
+	}{{
+		name:     "newline-delimited JSON",
+		args:     []string{"filter", "{severity=\"critical\"}"},
+		stdin:    ndjson,
+		code:     exitOK,
+		expected: "{\"labels\":{\"severity\":\"critical\",\"team\":\"core\"},\"status\":\"active\"}\n",
+	}, {
+		name:     "JSON array",
+		args:     []string{"filter", "{severity=\"critical\"}"},
+		stdin:    array,
+		code:     exitOK,
+		expected: "{\"labels\":{\"severity\":\"critical\",\"team\":\"core\"},\"status\":\"active\"}\n",
+	}, {
+		name:     "invert",
+		args:     []string{"filter", "--invert", "{severity=\"critical\"}"},
+		stdin:    ndjson,
+		code:     exitOK,
+		expected: "{\"labels\":{\"severity\":\"warning\"}}\n{\"fingerprint\":\"abc\"}\n",
+	}, {
+		name:     "count after the matchers",
+		args:     []string{"filter", "{severity=~\"critical|warning\"}", "--count"},
+		stdin:    array,
+		code:     exitOK,
+		expected: "2\n",
+	}, {
+		name:     "no matches",
+		args:     []string{"filter", "-count", "{severity=\"info\"}"},
+		stdin:    ndjson,
+		code:     exitNoMatch,
+		expected: "0\n",
+	}, {
+		name:  "empty input",
+		args:  []string{"filter", "{severity=\"info\"}"},
+		stdin: "\n",
+		code:  exitNoMatch,
+	}, {
+		name:  "parse error",
+		args:  []string{"filter", "{severity=}"},
+		code:  exitParseError,
+		error: "matchers filter: 1:10-1:11: unexpected }: expected label value\n",
+	}, {
+		name:  "invalid labels",
+		args:  []string{"filter", "{severity=\"critical\"}"},
+		stdin: `{"labels":{"":"critical"}}`,
+		code:  exitError,
+		error: "matchers filter: alert 1: \"\" is not a valid label name\n",
+	}, {
+		name: "invalid labels after alerts that do not match",
+		args: []string{"filter", "{severity=\"critical\"}"},
+		stdin: `{"labels":{"severity":"critical"}}
+{"labels":{"severity":"info"}}
+{"labels":{"":"critical"}}`,
+		code:     exitError,
+		expected: "{\"labels\":{\"severity\":\"critical\"}}\n",
+		error:    "matchers filter: alert 3: \"\" is not a valid label name\n",
+	}, {
+		name:  "array without close bracket",
+		args:  []string{"filter", "{severity=\"critical\"}"},
+		stdin: `[{"labels":{}}`,
+		code:  exitError,
+		error: "matchers filter: unexpected end of JSON input\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+			assert.Equal(t, test.code, code)
+			assert.Equal(t, test.expected, stdout.String())
+			assert.Equal(t, test.error, stderr.String())
+		})
+	}
+}
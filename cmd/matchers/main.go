@@ -0,0 +1,274 @@
+// Command matchers parses, formats and tests Prometheus-like matchers.
+//
+// Usage:
+//
+//	matchers parse [input]
+//	matchers fmt [-w] [file ...]
+//	matchers test input labels.json
+//	matchers filter [--invert] [--count] input
+//
+// The exit code is 0 on success, 1 if a label set does not match, 2 if the
+// matchers cannot be parsed, and 3 for other errors such as invalid arguments.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grobinson-grafana/matchers"
+)
+
+const (
+	exitOK         = 0
+	exitNoMatch    = 1
+	exitParseError = 2
+	exitError      = 3
+)
+
+const usage = `usage: matchers <command> [arguments]
+
+commands:
+  parse [input]              print the matchers as JSON
+  fmt [-w] [file ...]        format the matchers on each line
+  test input labels.json     test if the matchers match each label set
+  filter [--invert] [--count] input
+                             print the alerts on stdin that match
+`
+
+// parseError is an error parsing matchers. Its exit code is exitParseError.
+type parseError struct {
+	err error
+}
+
+func (e parseError) Error() string {
+	return e.err.Error()
+}
+
+// errNoMatch is returned when a label set does not match. Its exit code is
+// exitNoMatch.
+var errNoMatch = errors.New("no match")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command in args and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+	var cmd func(args []string, stdin io.Reader, stdout io.Writer) error
+	switch args[0] {
+	case "parse":
+		cmd = runParse
+	case "fmt":
+		cmd = runFmt
+	case "test":
+		cmd = runTest
+	case "filter":
+		cmd = runFilter
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "matchers: unknown command %q\n\n%s", args[0], usage)
+		return exitError
+	}
+	err := cmd(args[1:], stdin, stdout)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if errors.Is(err, errNoMatch) {
+		return exitNoMatch
+	}
+	fmt.Fprintf(stderr, "matchers %s: %s\n", args[0], err)
+	var perr parseError
+	if errors.As(err, &perr) {
+		return exitParseError
+	}
+	return exitError
+}
+
+// parseFlags parses the flags in args, which unlike fs.Parse can be after the
+// arguments, and returns the arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var result []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return result, nil
+		}
+		result = append(result, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newFlagSet returns a flag set for the command name that returns errors
+// rather than exiting.
+func newFlagSet(name string, stdout io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	return fs
+}
+
+// runParse prints the matchers in the argument, or stdin if there is no
+// argument, as JSON.
+func runParse(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("parse", stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var input string
+	switch fs.NArg() {
+	case 0:
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		input = strings.TrimSpace(string(b))
+	case 1:
+		input = fs.Arg(0)
+	default:
+		return errors.New("expected at most one input")
+	}
+	ms, err := matchers.ParseMatchers(input)
+	if err != nil {
+		return parseError{err}
+	}
+	enc := json.NewEncoder(stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ms)
+}
+
+// runFmt formats the matchers on each line of the files, or stdin if there
+// are no files. Empty lines and lines starting with '#' are not changed.
+func runFmt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("fmt", stdout)
+	write := fs.Bool("w", false, "write the result to the file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		if *write {
+			return errors.New("cannot use -w with stdin")
+		}
+		// The input is formatted before it is written so nothing is written
+		// if it cannot be parsed
+		var out bytes.Buffer
+		if err := formatLines("<stdin>", stdin, &out); err != nil {
+			return err
+		}
+		_, err := stdout.Write(out.Bytes())
+		return err
+	}
+	for _, name := range fs.Args() {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err = formatLines(name, bytes.NewReader(b), &out); err != nil {
+			return err
+		}
+		if !*write {
+			if _, err = stdout.Write(out.Bytes()); err != nil {
+				return err
+			}
+		} else if !bytes.Equal(b, out.Bytes()) {
+			if err = os.WriteFile(name, out.Bytes(), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatLines writes each line in r to w with its matchers formatted. It
+// returns the first parse error, where name is the name of the input.
+func formatLines(name string, r io.Reader, w io.Writer) error {
+	var (
+		s    = bufio.NewScanner(r)
+		line = 0
+	)
+	for s.Scan() {
+		line++
+		text := s.Text()
+		if trimmed := strings.TrimSpace(text); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			ms, err := matchers.ParseMatchers(trimmed)
+			if err != nil {
+				return parseError{fmt.Errorf("%s:%d: %w", name, line, err)}
+			}
+			text = ms.String()
+		}
+		if _, err := fmt.Fprintln(w, text); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// runTest prints if the matchers match each label set in the JSON file. The
+// file can contain a label set or an array of label sets. It returns
+// errNoMatch if a label set does not match.
+func runTest(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("test", stdout)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("expected matchers and a JSON file of label sets")
+	}
+	ms, err := matchers.ParseMatchers(fs.Arg(0))
+	if err != nil {
+		return parseError{err}
+	}
+	lsets, err := readLabelSets(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	result := error(nil)
+	for _, lset := range lsets {
+		status := "match"
+		if !ms.Matches(lset) {
+			status = "no match"
+			result = errNoMatch
+		}
+		if _, err = fmt.Fprintf(stdout, "%s\t%s\n", status, lset); err != nil {
+			return err
+		}
+	}
+	return result
+}
+
+// readLabelSets returns the label sets in the JSON file name.
+func readLabelSets(name string) ([]model.LabelSet, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("[")) {
+		var lsets []model.LabelSet
+		if err = json.Unmarshal(b, &lsets); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return lsets, nil
+	}
+	var lset model.LabelSet
+	if err = json.Unmarshal(b, &lset); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return []model.LabelSet{lset}, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	labelsFile := filepath.Join(dir, "labels.json")
+	require.NoError(t, os.WriteFile(labelsFile, []byte(`[{"env":"prod","team":"core"},{"env":"dev"}]`), 0o644))
+	labelFile := filepath.Join(dir, "label.json")
+	require.NoError(t, os.WriteFile(labelFile, []byte(`{"env":"prod"}`), 0o644))
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		code     int
+		expected string
+		error    string
+	}{{
+		name: "parse",
+		args: []string{"parse", "{env=prod, not {team=~\"core|db\"}}"},
+		code: exitOK,
+		expected: `[
+  {
+    "name": "env",
+    "type": "=",
+    "value": "prod"
+  },
+  {
+    "type": "not",
+    "matchers": [
+      {
+        "name": "team",
+        "type": "=~",
+        "value": "core|db"
+      }
+    ]
+  }
+]
+`,
+	}, {
+		name:     "parse stdin",
+		args:     []string{"parse"},
+		stdin:    "foo^=bar\n",
+		code:     exitOK,
+		expected: "[\n  {\n    \"name\": \"foo\",\n    \"type\": \"^=\",\n    \"value\": \"bar\"\n  }\n]\n",
+	}, {
+		name:  "parse error",
+		args:  []string{"parse", "{foo=}"},
+		code:  exitParseError,
+		error: "matchers parse: 1:5-1:6: unexpected }: expected label value\n",
+	}, {
+		name:     "fmt stdin",
+		args:     []string{"fmt"},
+		stdin:    "# comment\n{ a = 1,b!~'x' }\n\nfoo=bar\n",
+		code:     exitOK,
+		expected: "# comment\n{a=\"1\", b!~\"x\"}\n\n{foo=\"bar\"}\n",
+	}, {
+		name:  "fmt parse error",
+		args:  []string{"fmt"},
+		stdin: "foo=bar\n{foo\n",
+		code:  exitParseError,
+		error: "matchers fmt: <stdin>:2: 1:0-1:4: end of input: expected an operator such as '=', '!=', '=~' or '!~'\n",
+	}, {
+		name:  "fmt -w with stdin",
+		args:  []string{"fmt", "-w"},
+		code:  exitError,
+		error: "matchers fmt: cannot use -w with stdin\n",
+	}, {
+		name:     "test matches",
+		args:     []string{"test", "{env=prod}", labelFile},
+		code:     exitOK,
+		expected: "match\t{env=\"prod\"}\n",
+	}, {
+		name:     "test does not match",
+		args:     []string{"test", "{env=prod}", labelsFile},
+		code:     exitNoMatch,
+		expected: "match\t{env=\"prod\", team=\"core\"}\nno match\t{env=\"dev\"}\n",
+	}, {
+		name:  "test parse error",
+		args:  []string{"test", "{env=}", labelsFile},
+		code:  exitParseError,
+		error: "matchers test: 1:5-1:6: unexpected }: expected label value\n",
+	}, {
+		name:  "test without label sets",
+		args:  []string{"test", "{env=prod}"},
+		code:  exitError,
+		error: "matchers test: expected matchers and a JSON file of label sets\n",
+	}, {
+		name:  "unknown command",
+		args:  []string{"foo"},
+		code:  exitError,
+		error: "matchers: unknown command \"foo\"\n\n" + usage,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+			assert.Equal(t, test.code, code)
+			assert.Equal(t, test.expected, stdout.String())
+			assert.Equal(t, test.error, stderr.String())
+		})
+	}
+}
+
+func TestRun_FmtWrite(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "matchers.txt")
+	require.NoError(t, os.WriteFile(name, []byte("# silences\na=1\n{b!=\"2\"}\n"), 0o644))
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"fmt", "-w", name}, nil, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "# silences\n{a=\"1\"}\n{b!=\"2\"}\n", string(b))
+}
@@ -0,0 +1,236 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrIncompatibleRegexp = errors.New("regex cannot be written in Lucene syntax")
+)
+
+// ElasticsearchQuery returns the matchers as a bool query in the
+// Elasticsearch and OpenSearch query DSL, which can be encoded as JSON. The
+// labels are keyword fields whose names are the label names after prefix,
+// such as labels.env. Like Alertmanager, a label that is not in a document
+// has the empty value, so matchers that match the empty value also match
+// documents without the field. Regexes are written in Lucene syntax, which
+// like Alertmanager matches the whole value. It returns an error if a regex
+// cannot be written in Lucene syntax, such as regexes with \b or anchors that
+// are not at the start or end.
+func ElasticsearchQuery(matchers labels.Matchers, prefix string) (map[string]any, error) {
+	var filter, mustNot []any
+	for _, m := range matchers {
+		field := prefix + m.Name
+		var q map[string]any
+		switch m.Type {
+		case labels.MatchEqual, labels.MatchNotEqual:
+			q = map[string]any{"term": map[string]any{field: m.Value}}
+		case labels.MatchRegexp, labels.MatchNotRegexp:
+			re, err := luceneRegexp(m.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", m, err)
+			}
+			q = map[string]any{"regexp": map[string]any{field: map[string]any{"value": re}}}
+		default:
+			return nil, fmt.Errorf("unknown match type: %s", m.Type)
+		}
+		missing := map[string]any{"bool": map[string]any{
+			"must_not": []any{map[string]any{"exists": map[string]any{"field": field}}},
+		}}
+		isNegative := m.Type == labels.MatchNotEqual || m.Type == labels.MatchNotRegexp
+		switch {
+		case !isNegative && m.Matches(""):
+			// Documents without the field also match
+			filter = append(filter, map[string]any{"bool": map[string]any{
+				"should":               []any{q, missing},
+				"minimum_should_match": 1,
+			}})
+		case !isNegative:
+			filter = append(filter, q)
+		case m.Matches(""):
+			mustNot = append(mustNot, q)
+		default:
+			// Documents without the field have the empty value which q
+			// matches, so they must not match either
+			filter = append(filter, map[string]any{"exists": map[string]any{"field": field}})
+			mustNot = append(mustNot, q)
+		}
+	}
+	if len(filter) == 0 && len(mustNot) == 0 {
+		return map[string]any{"match_all": map[string]any{}}, nil
+	}
+	query := make(map[string]any)
+	if len(filter) > 0 {
+		query["filter"] = filter
+	}
+	if len(mustNot) > 0 {
+		query["must_not"] = mustNot
+	}
+	return map[string]any{"bool": query}, nil
+}
+
+// luceneRegexp returns the Go regex s in Lucene syntax. Lucene regexes match
+// the whole value, so ^ at the start and $ at the end are removed. It returns
+// an error for other anchors and word boundaries as Lucene does not have them.
+func luceneRegexp(s string) (string, error) {
+	re, err := syntax.Parse(s, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	re = trimAnchors(re.Simplify())
+	var b strings.Builder
+	if err = writeLucene(&b, re); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// trimAnchors removes ^ at the start and $ at the end of re.
+func trimAnchors(re *syntax.Regexp) *syntax.Regexp {
+	switch re.Op {
+	case syntax.OpBeginText, syntax.OpEndText:
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	case syntax.OpConcat:
+		sub := re.Sub
+		if len(sub) > 0 && sub[0].Op == syntax.OpBeginText {
+			sub = sub[1:]
+		}
+		if len(sub) > 0 && sub[len(sub)-1].Op == syntax.OpEndText {
+			sub = sub[:len(sub)-1]
+		}
+		c := *re
+		c.Sub = sub
+		return &c
+	}
+	return re
+}
+
+// luceneReserved contains the runes that must be escaped in Lucene regexes.
+const luceneReserved = `.?+*|{}[]()"\#@&<>~`
+
+func writeLucene(b *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		// # is the empty language in Lucene
+		b.WriteString("#")
+	case syntax.OpEmptyMatch:
+		b.WriteString(`""`)
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && unicode.SimpleFold(r) != r {
+				// Case-insensitive literals are written as character classes
+				// of all the cases of the rune
+				b.WriteString("[")
+				for f := r; ; {
+					writeLuceneRune(b, f)
+					if f = unicode.SimpleFold(f); f == r {
+						break
+					}
+				}
+				b.WriteString("]")
+			} else {
+				writeLuceneRune(b, r)
+			}
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			b.WriteString("#")
+			return nil
+		}
+		b.WriteString("[")
+		for i := 0; i < len(re.Rune); i += 2 {
+			writeLuceneRune(b, re.Rune[i])
+			if re.Rune[i+1] != re.Rune[i] {
+				b.WriteString("-")
+				writeLuceneRune(b, re.Rune[i+1])
+			}
+		}
+		b.WriteString("]")
+	case syntax.OpAnyChar:
+		b.WriteString(".")
+	case syntax.OpAnyCharNotNL:
+		b.WriteString("[^\n]")
+	case syntax.OpCapture:
+		b.WriteString("(")
+		if err := writeLucene(b, re.Sub[0]); err != nil {
+			return err
+		}
+		b.WriteString(")")
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := writeLuceneOperand(b, re.Sub[0]); err != nil {
+			return err
+		}
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteString("*")
+		case syntax.OpPlus:
+			b.WriteString("+")
+		case syntax.OpQuest:
+			b.WriteString("?")
+		default:
+			b.WriteString("{" + strconv.Itoa(re.Min))
+			if re.Max != re.Min {
+				b.WriteString(",")
+				if re.Max >= 0 {
+					b.WriteString(strconv.Itoa(re.Max))
+				}
+			}
+			b.WriteString("}")
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpAlternate {
+				b.WriteString("(")
+			}
+			if err := writeLucene(b, sub); err != nil {
+				return err
+			}
+			if sub.Op == syntax.OpAlternate {
+				b.WriteString(")")
+			}
+		}
+	case syntax.OpAlternate:
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			if err := writeLucene(b, sub); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrIncompatibleRegexp, re)
+	}
+	return nil
+}
+
+// writeLuceneOperand writes the operand of a repetition, in parens if it has
+// more than one rune.
+func writeLuceneOperand(b *strings.Builder, re *syntax.Regexp) error {
+	if re.Op == syntax.OpConcat || re.Op == syntax.OpAlternate ||
+		(re.Op == syntax.OpLiteral && len(re.Rune) > 1) {
+		b.WriteString("(")
+		if err := writeLucene(b, re); err != nil {
+			return err
+		}
+		b.WriteString(")")
+		return nil
+	}
+	return writeLucene(b, re)
+}
+
+// writeLuceneRune writes r with a '\' if it is reserved in Lucene, or is a
+// '-' or '^' that could be an operator in a character class.
+func writeLuceneRune(b *strings.Builder, r rune) {
+	if strings.ContainsRune(luceneReserved+"-^", r) {
+		b.WriteRune('\\')
+	}
+	b.WriteRune(r)
+}
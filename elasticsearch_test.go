@@ -0,0 +1,80 @@
+package matchers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearchQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		error    string
+	}{{
+		name:     "no matchers",
+		input:    "{}",
+		expected: `{"match_all":{}}`,
+	}, {
+		name:  "equal and not equal",
+		input: "{env=prod,team!=core}",
+		expected: `{"bool":{"filter":[{"term":{"labels.env":"prod"}}],` +
+			`"must_not":[{"term":{"labels.team":"core"}}]}}`,
+	}, {
+		name:  "empty values",
+		input: "{env=\"\",team!=\"\"}",
+		expected: `{"bool":{"filter":[` +
+			`{"bool":{"minimum_should_match":1,"should":[{"term":{"labels.env":""}},` +
+			`{"bool":{"must_not":[{"exists":{"field":"labels.env"}}]}}]}},` +
+			`{"exists":{"field":"labels.team"}}],` +
+			`"must_not":[{"term":{"labels.team":""}}]}}`,
+	}, {
+		name:  "regexes",
+		input: "{service=~\"^(api|web)-.+$\",instance!~\"host-\\\\d{2}\"}",
+		expected: `{"bool":{"filter":[{"regexp":{"labels.service":{"value":"(api|web)\\-[^\n]+"}}}],` +
+			`"must_not":[{"regexp":{"labels.instance":{"value":"host\\-[0-9][0-9]"}}}]}}`,
+	}, {
+		name:  "regexes that match the empty value",
+		input: "{env=~\"prod|\",team!~\".*\"}",
+		expected: `{"bool":{"filter":[` +
+			`{"bool":{"minimum_should_match":1,"should":[{"regexp":{"labels.env":{"value":"prod|\"\""}}},` +
+			`{"bool":{"must_not":[{"exists":{"field":"labels.env"}}]}}]}},` +
+			`{"exists":{"field":"labels.team"}}],` +
+			`"must_not":[{"regexp":{"labels.team":{"value":"[^\n]*"}}}]}}`,
+	}, {
+		name:     "reserved runes are escaped",
+		input:    "{path=~\"/a\\\"b#c@d&e<f>g~h\",url^=\"a.b\"}",
+		expected: `{"bool":{"filter":[{"regexp":{"labels.path":{"value":"/a\\\"b\\#c\\@d\\\u0026e\\\u003cf\\\u003eg\\~h"}}},{"regexp":{"labels.url":{"value":"a\\.b.*"}}}]}}`,
+	}, {
+		name:     "case-insensitive",
+		input:    "{severity~=\"crit\"}",
+		expected: `{"bool":{"filter":[{"regexp":{"labels.severity":{"value":"[Cc][Rr][Ii][Tt]"}}}]}}`,
+	}, {
+		name:  "word boundary",
+		input: "{message=~\".*\\\\bfoo\\\\b.*\"}",
+		error: "message=~\".*\\\\bfoo\\\\b.*\": regex cannot be written in Lucene syntax: \\b",
+	}, {
+		name:  "anchor in the middle",
+		input: "{message=~\"a$|b\"}",
+		error: "message=~\"a$|b\": regex cannot be written in Lucene syntax: (?-m:$)",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms, err := Parse(test.input)
+			require.NoError(t, err)
+			q, err := ElasticsearchQuery(ms, "labels.")
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+				return
+			}
+			require.NoError(t, err)
+			b, err := json.Marshal(q)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(b))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrVersionText = errors.New("version comparisons cannot be written as text")
+)
+
+// MarshalText returns the matchers as text that can be parsed with
+// UnmarshalText. It returns an error if a matcher is a version comparison, as
+// the text does not say if a comparison compares versions or numbers.
+func (ms Matchers) MarshalText() ([]byte, error) {
+	if err := checkNoVersions(ms); err != nil {
+		return nil, err
+	}
+	return []byte(ms.String()), nil
+}
+
+// checkNoVersions returns an error if a matcher, including the matchers in
+// not groups, is a version comparison.
+func checkNoVersions(ms Matchers) error {
+	for _, m := range ms {
+		if m.version != nil {
+			return fmt.Errorf("%s: %w", m, ErrVersionText)
+		}
+		if err := checkNoVersions(m.Group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalText parses the matchers in text with ParseMatchers.
+func (ms *Matchers) UnmarshalText(text []byte) error {
+	result, err := ParseMatchers(string(text))
+	if err != nil {
+		return err
+	}
+	*ms = result
+	return nil
+}
+
+// Set parses the matchers in s and adds them to the matchers, so a flag can be
+// used more than once such as -matchers env=prod -matchers team=core. It
+// implements flag.Value.
+func (ms *Matchers) Set(s string) error {
+	result, err := ParseMatchers(s)
+	if err != nil {
+		return err
+	}
+	*ms = append(*ms, result...)
+	return nil
+}
+
+// UnmarshalYAML parses the matchers in a YAML string, or in each string in a
+// YAML sequence of strings. The positions in errors have the lines in the YAML
+// document, such as 3:15-3:16 for an error on line 3. Columns are the columns
+// in the YAML document for strings on one line, but do not count the
+// indentation in block strings such as |. Columns after an escape in a quoted
+// string, such as \" in double quotes or a doubled quote in single quotes, are
+// the columns in the string without the escapes as YAML does not keep the
+// original text.
+func (ms *Matchers) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		result, err := parseYAMLMatchers(value)
+		if err != nil {
+			return err
+		}
+		*ms = result
+	case yaml.SequenceNode:
+		var result Matchers
+		for _, node := range value.Content {
+			if node.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: expected a string of matchers", node.Line)
+			}
+			m, err := parseYAMLMatchers(node)
+			if err != nil {
+				return err
+			}
+			result = append(result, m...)
+		}
+		*ms = result
+	default:
+		return fmt.Errorf("line %d: expected a string or a sequence of strings of matchers", value.Line)
+	}
+	return nil
+}
+
+// parseYAMLMatchers parses the matchers in a YAML string. The positions in
+// errors are the positions in the YAML document, where the line and column of
+// node start at 1 and the columns in positions start at 0.
+func parseYAMLMatchers(node *yaml.Node) (Matchers, error) {
+	start := Position{LineStart: node.Line, ColumnStart: node.Column - 1}
+	switch node.Style {
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		// The matchers start after the quote
+		start.ColumnStart++
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		// The matchers start on the line after | or >
+		start.LineStart++
+		start.ColumnStart = 0
+	}
+	p := newParserAt(node.Value, start)
+	return p.ParseMatchers()
+}
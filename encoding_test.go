@@ -0,0 +1,133 @@
+package matchers
+
+import (
+	"encoding"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ encoding.TextMarshaler   = Matchers{}
+	_ encoding.TextUnmarshaler = &Matchers{}
+	_ flag.Value               = &Matchers{}
+	_ yaml.Unmarshaler         = &Matchers{}
+)
+
+func TestMatchers_Text(t *testing.T) {
+	ms := Matchers{
+		mustNewMatcherOfType(t, MatchEqual, "foo", "bar"),
+		mustNewMatcherOfType(t, MatchNotRegexp, "bar", "[a-z]+"),
+	}
+	b, err := ms.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "{foo=\"bar\", bar!~\"[a-z]+\"}", string(b))
+	var parsed Matchers
+	require.NoError(t, parsed.UnmarshalText(b))
+	assert.EqualValues(t, ms, parsed)
+	require.EqualError(t, parsed.UnmarshalText([]byte("{foo=}")), "1:5-1:6: unexpected }: expected label value")
+}
+
+func TestMatchers_MarshalTextVersions(t *testing.T) {
+	// Version comparisons would be parsed as number comparisons
+	ms, err := ParseMatchers("{version>=\"1.2.3\"}", WithVersions())
+	require.NoError(t, err)
+	_, err = ms.MarshalText()
+	require.ErrorIs(t, err, ErrVersionText)
+	require.EqualError(t, err, "version>=\"1.2.3\": version comparisons cannot be written as text")
+	ms, err = ParseMatchers("{env=prod, not {version<\"2\"}}", WithVersions())
+	require.NoError(t, err)
+	_, err = ms.MarshalText()
+	require.ErrorIs(t, err, ErrVersionText)
+	// Number comparisons can be written as text
+	ms, err = ParseMatchers("{priority>=\"2\"}")
+	require.NoError(t, err)
+	b, err := ms.MarshalText()
+	require.NoError(t, err)
+	var parsed Matchers
+	require.NoError(t, parsed.UnmarshalText(b))
+	assert.Equal(t, ms, parsed)
+}
+
+func TestMatchers_Set(t *testing.T) {
+	var ms Matchers
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&ms, "matchers", "")
+	require.NoError(t, fs.Parse([]string{"-matchers", "env=prod", "-matchers", "{team=core,severity!=info}"}))
+	assert.EqualValues(t, Matchers{
+		mustNewMatcherOfType(t, MatchEqual, "env", "prod"),
+		mustNewMatcherOfType(t, MatchEqual, "team", "core"),
+		mustNewMatcherOfType(t, MatchNotEqual, "severity", "info"),
+	}, ms)
+	assert.Equal(t, "{env=\"prod\", team=\"core\", severity!=\"info\"}", ms.String())
+}
+
+func TestMatchers_UnmarshalYAML(t *testing.T) {
+	type config struct {
+		Route struct {
+			Matchers Matchers `yaml:"matchers"`
+		} `yaml:"route"`
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected Matchers
+		error    string
+	}{{
+		name:  "string",
+		input: "route:\n  matchers: '{env=prod, team=~\"core|db\"}'\n",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "env", "prod"),
+			mustNewMatcherOfType(t, MatchRegexp, "team", "core|db"),
+		},
+	}, {
+		name:  "sequence",
+		input: "route:\n  matchers:\n    - env=prod\n    - \"team!=core\"\n",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "env", "prod"),
+			mustNewMatcherOfType(t, MatchNotEqual, "team", "core"),
+		},
+	}, {
+		name:  "error in plain string",
+		input: "route:\n  matchers: env=prod, team=\n",
+		error: "2:12-2:27: end of input: expected label value",
+	}, {
+		name:  "error in quoted string",
+		input: "route:\n  matchers: \"{env=prod, team}\"\n",
+		error: "2:28-2:29: unexpected }: expected an operator such as '=', '!=', '=~' or '!~'",
+	}, {
+		// The columns are the columns in the string without the escapes, so
+		// the } at column 32 is reported at column 30
+		name:  "error in quoted string after escapes",
+		input: "route:\n  matchers: \"{env=\\\"prod\\\", team}\"\n",
+		error: "2:30-2:31: unexpected }: expected an operator such as '=', '!=', '=~' or '!~'",
+	}, {
+		name:  "error in sequence",
+		input: "route:\n  matchers:\n    - env=prod\n    - team=~\"[a-\"\n",
+		error: "4:13-4:16: [a-: invalid regex: missing closing ]",
+	}, {
+		name:  "error in block string",
+		input: "route:\n  matchers: |\n    env=prod,\n    team=\n",
+		error: "3:0-5:0: end of input: expected label value",
+	}, {
+		name:  "mapping",
+		input: "route:\n  matchers:\n    env: prod\n",
+		error: "line 3: expected a string or a sequence of strings of matchers",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var c config
+			err := yaml.Unmarshal([]byte(test.input), &c)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, c.Route.Matchers)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package matchers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+)
+
+// Format returns the matchers as a string that can be parsed with Parse. Label
+// names are quoted if they are not idents, and label values are always quoted.
+func Format(matchers labels.Matchers) string {
+	return "{" + formatMatchers(matchers) + "}"
+}
+
+// FormatSelector returns the matchers as a PromQL series selector. If the
+// matchers contain an equality matcher for __name__ whose value is an ident
+// then it is written as the metric name before the open brace,
+// such as http_requests_total{job="api"}. The string can be parsed with Parse
+// using the WithMetricName option.
+func FormatSelector(matchers labels.Matchers) string {
+	return formatSelector(matchers, isIdent)
+}
+
+// formatSelector is like FormatSelector but the value of __name__ is written
+// as the metric name if isMetricName returns true for it.
+func formatSelector(matchers labels.Matchers, isMetricName func(s string) bool) string {
+	for i, m := range matchers {
+		if m.Name == model.MetricNameLabel && m.Type == labels.MatchEqual && isMetricName(m.Value) {
+			rest := make(labels.Matchers, 0, len(matchers)-1)
+			rest = append(rest, matchers[:i]...)
+			rest = append(rest, matchers[i+1:]...)
+			if len(rest) == 0 {
+				return m.Value
+			}
+			return m.Value + Format(rest)
+		}
+	}
+	return Format(matchers)
+}
+
+func formatMatchers(matchers labels.Matchers) string {
+	var b strings.Builder
+	for i, m := range matchers {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(formatMatcher(m))
+	}
+	return b.String()
+}
+
+func formatMatcher(m *labels.Matcher) string {
+	return formatLabelName(m.Name) + m.Type.String() + strconv.Quote(m.Value)
+}
+
+// formatLabelName returns the label name in quotes if it is not an ident.
+func formatLabelName(name string) string {
+	if isIdent(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// isIdent returns true if s can be scanned as a TokenIdent.
+func isIdent(s string) bool {
+	for i, r := range s {
+		if !isAlpha(r) && r != '_' && (i == 0 || !isNum(r) && r != ':') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,97 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers labels.Matchers
+		expected string
+	}{{
+		name:     "no matchers",
+		expected: "{}",
+	}, {
+		name:     "equals",
+		matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar")},
+		expected: "{foo=\"bar\"}",
+	}, {
+		name: "all match types",
+		matchers: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "foo", "bar"),
+			mustNewMatcher(t, labels.MatchNotEqual, "bar", "baz"),
+			mustNewMatcher(t, labels.MatchRegexp, "baz", "[a-z]+"),
+			mustNewMatcher(t, labels.MatchNotRegexp, "qux", "\\d+"),
+		},
+		expected: "{foo=\"bar\", bar!=\"baz\", baz=~\"[a-z]+\", qux!~\"\\\\d+\"}",
+	}, {
+		name:     "quoted label name",
+		matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo bar", "🙂")},
+		expected: "{\"foo bar\"=\"🙂\"}",
+	}, {
+		name:     "escaped label value",
+		matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "\"bar\"\n")},
+		expected: "{foo=\"\\\"bar\\\"\\n\"}",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := Format(test.matchers)
+			assert.Equal(t, test.expected, s)
+			// The string must parse to the same matchers
+			matchers, err := Parse(s)
+			require.NoError(t, err)
+			assert.EqualValues(t, test.matchers, matchers)
+		})
+	}
+}
+
+func TestFormatSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers labels.Matchers
+		expected string
+	}{{
+		name:     "no matchers",
+		expected: "{}",
+	}, {
+		name:     "metric name",
+		matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "__name__", "http_requests_total")},
+		expected: "http_requests_total",
+	}, {
+		name: "metric name and matchers",
+		matchers: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "__name__", "job:http_requests:rate5m"),
+			mustNewMatcher(t, labels.MatchEqual, "job", "api"),
+		},
+		expected: "job:http_requests:rate5m{job=\"api\"}",
+	}, {
+		name: "regex on metric name",
+		matchers: labels.Matchers{
+			mustNewMatcher(t, labels.MatchRegexp, "__name__", "http_.*"),
+			mustNewMatcher(t, labels.MatchEqual, "job", "api"),
+		},
+		expected: "{__name__=~\"http_.*\", job=\"api\"}",
+	}, {
+		name: "metric name that is not an ident",
+		matchers: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "__name__", "http.requests"),
+		},
+		expected: "{__name__=\"http.requests\"}",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := FormatSelector(test.matchers)
+			assert.Equal(t, test.expected, s)
+			matchers, err := Parse(s, WithMetricName())
+			require.NoError(t, err)
+			assert.EqualValues(t, test.matchers, matchers)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrNoGroupName    = errors.New("expected group name")
+	ErrNoColon        = errors.New("expected ':' after group name")
+	ErrDuplicateGroup = errors.New("duplicate group name")
+	ErrUndefinedGroup = errors.New("undefined group")
+	ErrGroupCycle     = errors.New("group references itself")
+)
+
+// Group is a named series of matchers from a matchers file.
+type Group struct {
+	Name     string
+	Matchers labels.Matchers
+	Position Position // The position of the name
+}
+
+// groupDef is the definition of a group before its matchers are parsed.
+type groupDef struct {
+	name  string
+	pos   Position // the position of the name
+	input string   // the matchers after the ':'
+	start Position // the position of the matchers
+}
+
+// ParseGroups parses a matchers file and returns its groups by name. Each line
+// of a matchers file is either empty, a comment starting with '#', or a group
+// with a name followed by a ':' and a series of matchers:
+//
+//	# Matchers for the production environment
+//	prod: {env="prod"}
+//	prod_payments: {@prod, team="payments"} # all alerts for payments in prod
+//
+// A group can contain references to other groups in the same file, such as
+// @prod, which are replaced with the matchers from the referenced group. It
+// returns an error if a group cannot be parsed, if two groups have the same
+// name, or if a group references a group that does not exist or references
+// itself.
+func ParseGroups(input string) (map[string]Group, error) {
+	g := groupParser{
+		defs:      make(map[string]groupDef),
+		groups:    make(map[string]Group),
+		matchers:  make(map[string]Matchers),
+		resolving: make(map[string]bool),
+	}
+	var names []string
+	offset := 0
+	for i, line := range strings.SplitAfter(input, "\n") {
+		def, ok, err := parseGroupDef(line, Position{
+			OffsetStart: offset,
+			LineStart:   i + 1,
+		})
+		offset += len(line)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		if prev, ok := g.defs[def.name]; ok {
+			return nil, fmt.Errorf("%s: %s: %w, first defined at %s", def.pos, def.name, ErrDuplicateGroup, prev.pos)
+		}
+		g.defs[def.name] = def
+		names = append(names, def.name)
+	}
+	for _, name := range names {
+		if _, err := g.resolve(name, g.defs[name].pos); err != nil {
+			return nil, err
+		}
+	}
+	return g.groups, nil
+}
+
+// parseGroupDef parses the name of the group in line and returns its
+// definition. It returns false if the line is empty or just a comment.
+func parseGroupDef(line string, start Position) (groupDef, bool, error) {
+	line = stripComment(strings.TrimSuffix(line, "\n"))
+	if strings.TrimSpace(line) == "" {
+		return groupDef{}, false, nil
+	}
+	var (
+		i   int
+		col int
+	)
+	// pos returns the position of the rune at i
+	pos := func() Position {
+		_, width := utf8.DecodeRuneInString(line[i:])
+		return Position{
+			OffsetStart: start.OffsetStart + i,
+			OffsetEnd:   start.OffsetStart + i + width,
+			LineStart:   start.LineStart,
+			LineEnd:     start.LineStart,
+			ColumnStart: col,
+			ColumnEnd:   col + 1,
+		}
+	}
+	skipSpace := func() {
+		for i < len(line) {
+			r, width := utf8.DecodeRuneInString(line[i:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			i += width
+			col++
+		}
+	}
+	skipSpace()
+	def := groupDef{pos: pos()}
+	for i < len(line) {
+		r := rune(line[i])
+		if !isAlpha(r) && r != '_' && (!isNum(r) || def.name == "") {
+			break
+		}
+		def.name += string(r)
+		i++
+		col++
+	}
+	if def.name == "" {
+		r, _ := utf8.DecodeRuneInString(line[i:])
+		return groupDef{}, false, fmt.Errorf("%s: %c: %w", def.pos, r, ErrNoGroupName)
+	}
+	def.pos.OffsetEnd = def.pos.OffsetStart + len(def.name)
+	def.pos.ColumnEnd = def.pos.ColumnStart + len(def.name)
+	skipSpace()
+	if i >= len(line) {
+		return groupDef{}, false, fmt.Errorf("%s: end of line: %w", pos(), ErrNoColon)
+	} else if line[i] != ':' {
+		r, _ := utf8.DecodeRuneInString(line[i:])
+		return groupDef{}, false, fmt.Errorf("%s: %c: %w", pos(), r, ErrNoColon)
+	}
+	i++
+	col++
+	def.input = line[i:]
+	def.start = Position{
+		OffsetStart: start.OffsetStart + i,
+		LineStart:   start.LineStart,
+		ColumnStart: col,
+	}
+	return def, true, nil
+}
+
+// stripComment removes the comment at the end of line, if any. A '#' inside
+// quotes does not start a comment.
+func stripComment(line string) string {
+	var (
+		quote     rune
+		isEscaped bool
+	)
+	for i, r := range line {
+		if isEscaped {
+			isEscaped = false
+		} else if quote != 0 && quote != '`' && r == '\\' {
+			isEscaped = true
+		} else if quote == 0 && isQuote(r) {
+			quote = r
+		} else if r == quote {
+			quote = 0
+		} else if quote == 0 && r == '#' {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// groupParser parses the matchers for each group, replacing references with
+// the matchers from the referenced group.
+type groupParser struct {
+	defs      map[string]groupDef
+	groups    map[string]Group
+	matchers  map[string]Matchers
+	resolving map[string]bool
+}
+
+// resolve returns the matchers for the group name, parsing them if the group
+// has not been parsed. pos is the position of the reference to the group.
+func (g *groupParser) resolve(name string, pos Position) (Matchers, error) {
+	if matchers, ok := g.matchers[name]; ok {
+		return matchers, nil
+	}
+	def, ok := g.defs[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: @%s: %w", pos, name, ErrUndefinedGroup)
+	}
+	if g.resolving[name] {
+		return nil, fmt.Errorf("%s: @%s: %w", pos, name, ErrGroupCycle)
+	}
+	g.resolving[name] = true
+	defer delete(g.resolving, name)
+	p := newParserAt(def.input, def.start)
+	p.resolve = g.resolve
+	matchers, err := p.ParseMatchers()
+	if err != nil {
+		return nil, err
+	}
+	lm, err := matchers.Labels()
+	if err != nil {
+		return nil, err
+	}
+	g.matchers[name] = matchers
+	g.groups[name] = Group{
+		Name:     name,
+		Matchers: lm,
+		Position: def.pos,
+	}
+	return matchers, nil
+}
@@ -0,0 +1,169 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]Group
+		error    string
+	}{{
+		name:     "empty",
+		input:    "",
+		expected: map[string]Group{},
+	}, {
+		name:     "comments and blank lines",
+		input:    "# comment\n\n  # another comment\n",
+		expected: map[string]Group{},
+	}, {
+		name:  "group",
+		input: "prod: {env=\"prod\"}",
+		expected: map[string]Group{
+			"prod": {
+				Name:     "prod",
+				Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "env", "prod")},
+				Position: Position{
+					OffsetStart: 0,
+					OffsetEnd:   4,
+					LineStart:   1,
+					LineEnd:     1,
+					ColumnStart: 0,
+					ColumnEnd:   4,
+				},
+			},
+		},
+	}, {
+		name:  "group with comment",
+		input: "# comment\n  prod : env=\"#prod\" # comment",
+		expected: map[string]Group{
+			"prod": {
+				Name:     "prod",
+				Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "env", "#prod")},
+				Position: Position{
+					OffsetStart: 12,
+					OffsetEnd:   16,
+					LineStart:   2,
+					LineEnd:     2,
+					ColumnStart: 2,
+					ColumnEnd:   6,
+				},
+			},
+		},
+	}, {
+		name:  "empty group",
+		input: "none: {}",
+		expected: map[string]Group{
+			"none": {
+				Name: "none",
+				Position: Position{
+					OffsetStart: 0,
+					OffsetEnd:   4,
+					LineStart:   1,
+					LineEnd:     1,
+					ColumnStart: 0,
+					ColumnEnd:   4,
+				},
+			},
+		},
+	}, {
+		name:  "references",
+		input: "prod_payments: {@prod, team=\"payments\"}\nprod: {env=\"prod\"}\nall: {@prod_payments,@prod}",
+		expected: map[string]Group{
+			"prod": {
+				Name:     "prod",
+				Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "env", "prod")},
+				Position: Position{
+					OffsetStart: 40,
+					OffsetEnd:   44,
+					LineStart:   2,
+					LineEnd:     2,
+					ColumnStart: 0,
+					ColumnEnd:   4,
+				},
+			},
+			"prod_payments": {
+				Name: "prod_payments",
+				Matchers: labels.Matchers{
+					mustNewMatcher(t, labels.MatchEqual, "env", "prod"),
+					mustNewMatcher(t, labels.MatchEqual, "team", "payments"),
+				},
+				Position: Position{
+					OffsetStart: 0,
+					OffsetEnd:   13,
+					LineStart:   1,
+					LineEnd:     1,
+					ColumnStart: 0,
+					ColumnEnd:   13,
+				},
+			},
+			"all": {
+				Name: "all",
+				Matchers: labels.Matchers{
+					mustNewMatcher(t, labels.MatchEqual, "env", "prod"),
+					mustNewMatcher(t, labels.MatchEqual, "team", "payments"),
+					mustNewMatcher(t, labels.MatchEqual, "env", "prod"),
+				},
+				Position: Position{
+					OffsetStart: 59,
+					OffsetEnd:   62,
+					LineStart:   3,
+					LineEnd:     3,
+					ColumnStart: 0,
+					ColumnEnd:   3,
+				},
+			},
+		},
+	}, {
+		name:  "no group name",
+		input: "prod: {env=\"prod\"}\n: {env=\"dev\"}",
+		error: "2:0-2:1: :: expected group name",
+	}, {
+		name:  "no colon",
+		input: "prod {env=\"prod\"}",
+		error: "1:5-1:6: {: expected ':' after group name",
+	}, {
+		name:  "no colon at end of line",
+		input: "prod",
+		error: "1:4-1:5: end of line: expected ':' after group name",
+	}, {
+		name:  "duplicate group",
+		input: "prod: {env=\"prod\"}\nprod: {env=\"production\"}",
+		error: "2:0-2:4: prod: duplicate group name, first defined at 1:0-1:4",
+	}, {
+		name:  "undefined group",
+		input: "prod: {env=\"prod\"}\nprod_payments: {@production, team=\"payments\"}",
+		error: "2:16-2:27: @production: undefined group",
+	}, {
+		name:  "group references itself",
+		input: "a: {@b}\nb: {foo=bar, @a}",
+		error: "2:13-2:15: @a: group references itself",
+	}, {
+		name:  "invalid matchers",
+		input: "prod: {env=\"prod\"\n",
+		error: "1:5-1:17: end of input: expected close brace",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			groups, err := ParseGroups(test.input)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, groups)
+			}
+		})
+	}
+}
+
+func TestParse_Reference(t *testing.T) {
+	_, err := Parse("{@prod, team=\"payments\"}")
+	require.EqualError(t, err, "1:1-1:6: unexpected @prod: expected label name")
+}
@@ -0,0 +1,138 @@
+package matchers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonMatcher is the JSON representation of a Matcher. Not matchers have
+// just the type and the matchers in the group.
+type jsonMatcher struct {
+	Name     *string  `json:"name,omitempty"`
+	Type     string   `json:"type"`
+	Value    *string  `json:"value,omitempty"`
+	Version  bool     `json:"version,omitempty"`
+	Matchers Matchers `json:"matchers,omitempty"`
+}
+
+// MarshalJSON returns the matcher as a JSON object with its name, type and
+// value, such as {"name":"foo","type":"=~","value":"[a-z]+"}. The type is the
+// operator in the matcher, or exists, !exists or not. Version comparisons also
+// have "version":true, and not matchers have the matchers in the group instead
+// of a name and value.
+func (m *Matcher) MarshalJSON() ([]byte, error) {
+	v := jsonMatcher{Type: m.Type.String()}
+	if m.Type == MatchNot {
+		v.Matchers = m.Group
+	} else {
+		v.Name, v.Value, v.Version = &m.Name, &m.Value, m.version != nil
+	}
+	return marshalJSON(v)
+}
+
+// UnmarshalJSON decodes a matcher from the JSON object returned from
+// MarshalJSON. It returns an error if the type is unknown or the value is not
+// valid for the type, such as an invalid regex.
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var v jsonMatcher
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	ty, err := jsonMatchType(v.Type)
+	if err != nil {
+		return err
+	}
+	if ty == MatchNot {
+		*m = *NewNotMatcher(v.Matchers)
+		return nil
+	}
+	if v.Name == nil {
+		return fmt.Errorf("%s matcher has no name", ty)
+	}
+	var value string
+	if v.Value != nil {
+		value = *v.Value
+	}
+	var result *Matcher
+	if v.Version {
+		result, err = NewVersionMatcher(ty, *v.Name, value)
+	} else {
+		result, err = NewMatcher(ty, *v.Name, value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid matcher %s: %w", &Matcher{Type: ty, Name: *v.Name, Value: value}, err)
+	}
+	*m = *result
+	return nil
+}
+
+// jsonMatchType returns the match type for the type of a matcher in JSON.
+func jsonMatchType(s string) (MatchType, error) {
+	switch s {
+	case "exists":
+		return MatchExists, nil
+	case "!exists":
+		return MatchNotExists, nil
+	case "not":
+		return MatchNot, nil
+	}
+	ty, err := matchType(s)
+	if err != nil {
+		return -1, fmt.Errorf("unknown match type %q", s)
+	}
+	return ty, nil
+}
+
+// MarshalJSON returns the matchers as a JSON array of matchers. Unlike a nil
+// slice, nil matchers are an empty array.
+func (ms Matchers) MarshalJSON() ([]byte, error) {
+	if ms == nil {
+		return []byte("[]"), nil
+	}
+	// Encode as a different type so this method is not called again
+	return marshalJSON([]*Matcher(ms))
+}
+
+// marshalJSON is like json.Marshal but does not escape '<', '>' and '&' in
+// operators and values. They are still escaped when the matchers are encoded
+// with json.Marshal rather than an Encoder with SetEscapeHTML(false).
+func marshalJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// UnmarshalJSON decodes the matchers from either a JSON array of matchers, as
+// returned from MarshalJSON on each matcher, or a JSON string which is parsed
+// with ParseMatchers.
+func (ms *Matchers) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		result, err := ParseMatchers(s)
+		if err != nil {
+			return err
+		}
+		*ms = result
+		return nil
+	}
+	// Decode into a different type so this method is not called again
+	var result []*Matcher
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	for i, m := range result {
+		if m == nil {
+			return fmt.Errorf("matcher %d is null", i)
+		}
+	}
+	*ms = result
+	return nil
+}
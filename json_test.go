@@ -0,0 +1,130 @@
+package matchers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		matchers Matchers
+		expected string
+	}{{
+		name:     "nil",
+		matchers: nil,
+		expected: `[]`,
+	}, {
+		name: "matchers",
+		matchers: Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "foo", "bar"),
+			mustNewMatcherOfType(t, MatchNotRegexp, "bar", "[a-z]+"),
+			mustNewMatcherOfType(t, MatchEqual, "baz", ""),
+		},
+		expected: `[{"name":"foo","type":"=","value":"bar"},{"name":"bar","type":"!~","value":"[a-z]+"},` +
+			`{"name":"baz","type":"=","value":""}]`,
+	}, {
+		name: "exists, versions and not groups",
+		matchers: Matchers{
+			mustNewMatcherOfType(t, MatchExists, "foo", ""),
+			mustNewVersionMatcher(t, MatchGreaterEqual, "version", "1.2.3"),
+			NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchPrefix, "bar", "baz")}),
+			NewNotMatcher(nil),
+		},
+		expected: `[{"name":"foo","type":"exists","value":""},` +
+			`{"name":"version","type":"\u003e=","value":"1.2.3","version":true},` +
+			`{"type":"not","matchers":[{"name":"bar","type":"^=","value":"baz"}]},{"type":"not"}]`,
+	}, {
+		// Like in Alertmanager the regex is ^(?:a)|(b)$ which is valid
+		name:     "regex that is valid only with the anchors",
+		matchers: Matchers{mustNewMatcherOfType(t, MatchRegexp, "foo", "a)|(b")},
+		expected: `[{"name":"foo","type":"=~","value":"a)|(b"}]`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.Marshal(test.matchers)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(b))
+			// The JSON must decode to the same matchers
+			var ms Matchers
+			require.NoError(t, json.Unmarshal(b, &ms))
+			if test.matchers == nil {
+				assert.Empty(t, ms)
+			} else {
+				assert.EqualValues(t, test.matchers, ms)
+			}
+		})
+	}
+}
+
+func TestMatchers_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Matchers
+		error    string
+	}{{
+		name:  "array",
+		input: `[{"name":"foo","type":"=~","value":"[a-z]+"},{"name":"bar","type":"!=","value":"baz"}]`,
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchRegexp, "foo", "[a-z]+"),
+			mustNewMatcherOfType(t, MatchNotEqual, "bar", "baz"),
+		},
+	}, {
+		name:  "string",
+		input: `"{foo=~\"[a-z]+\", bar!=baz}"`,
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchRegexp, "foo", "[a-z]+"),
+			mustNewMatcherOfType(t, MatchNotEqual, "bar", "baz"),
+		},
+	}, {
+		name:     "missing value",
+		input:    `[{"name":"foo","type":"!="}]`,
+		expected: Matchers{mustNewMatcherOfType(t, MatchNotEqual, "foo", "")},
+	}, {
+		name:  "invalid regex",
+		input: `[{"name":"foo","type":"=~","value":"[a-z"}]`,
+		error: "invalid matcher foo=~\"[a-z\": error parsing regexp: missing closing ]: `[a-z`",
+	}, {
+		name:     "regex that is valid only with the anchors",
+		input:    `[{"name":"foo","type":"=~","value":"a)|(b"}]`,
+		expected: Matchers{mustNewMatcherOfType(t, MatchRegexp, "foo", "a)|(b")},
+	}, {
+		name:  "not a number",
+		input: `[{"name":"foo","type":"<","value":"bar"}]`,
+		error: "invalid matcher foo<\"bar\": expected a number",
+	}, {
+		name:  "unknown type",
+		input: `[{"name":"foo","type":"==","value":"bar"}]`,
+		error: "unknown match type \"==\"",
+	}, {
+		name:  "missing name",
+		input: `[{"type":"=","value":"bar"}]`,
+		error: "= matcher has no name",
+	}, {
+		name:  "null",
+		input: `[null]`,
+		error: "matcher 0 is null",
+	}, {
+		name:  "invalid string",
+		input: `"{foo=}"`,
+		error: "1:5-1:6: unexpected }: expected label value",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ms Matchers
+			err := json.Unmarshal([]byte(test.input), &ms)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, ms)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package matchers
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -19,11 +22,44 @@ func isNum(r rune) bool {
 	return r >= '0' && r <= '9'
 }
 
+func isQuote(r rune) bool {
+	return r == '"' || r == '\'' || r == '`'
+}
+
+// unquote returns the text in a TokenQuoted without its quotes and with
+// escape sequences replaced. Text in backticks is returned as is.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != s[len(s)-1] {
+		return "", strconv.ErrSyntax
+	}
+	switch s[0] {
+	case '"':
+		return strconv.Unquote(s)
+	case '`':
+		return s[1 : len(s)-1], nil
+	case '\'':
+		var b strings.Builder
+		for s = s[1 : len(s)-1]; s != ""; {
+			r, _, tail, err := strconv.UnquoteChar(s, '\'')
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+			s = tail
+		}
+		return b.String(), nil
+	default:
+		return "", strconv.ErrSyntax
+	}
+}
+
 // ExpectedError is returned when the next rune does not match what is expected.
 type ExpectedError struct {
 	input       string
 	offsetStart int
 	offsetEnd   int
+	lineStart   int
+	lineEnd     int
 	columnStart int
 	columnEnd   int
 	expected    string
@@ -31,14 +67,18 @@ type ExpectedError struct {
 
 func (e ExpectedError) Error() string {
 	if e.offsetEnd >= len(e.input) {
-		return fmt.Sprintf("%d:%d: unexpected end of input, expected one of '%s'",
+		return fmt.Sprintf("%d:%d-%d:%d: unexpected end of input, expected one of '%s'",
+			e.lineStart,
 			e.columnStart,
+			e.lineEnd,
 			e.columnEnd,
 			e.expected,
 		)
 	}
-	return fmt.Sprintf("%d:%d: %s: expected one of '%s'",
+	return fmt.Sprintf("%d:%d-%d:%d: %s: expected one of '%s'",
+		e.lineStart,
 		e.columnStart,
+		e.lineEnd,
 		e.columnEnd,
 		e.input[e.offsetStart:e.offsetEnd],
 		e.expected,
@@ -51,13 +91,17 @@ type InvalidInputError struct {
 	input       string
 	offsetStart int
 	offsetEnd   int
+	lineStart   int
+	lineEnd     int
 	columnStart int
 	columnEnd   int
 }
 
 func (e InvalidInputError) Error() string {
-	return fmt.Sprintf("%d:%d: %s: invalid input",
+	return fmt.Sprintf("%d:%d-%d:%d: %s: invalid input",
+		e.lineStart,
 		e.columnStart,
+		e.lineEnd,
 		e.columnEnd,
 		e.input[e.offsetStart:e.offsetEnd],
 	)
@@ -68,14 +112,18 @@ type UnterminatedError struct {
 	input       string
 	offsetStart int
 	offsetEnd   int
+	lineStart   int
+	lineEnd     int
 	columnStart int
 	columnEnd   int
 	quote       rune
 }
 
 func (e UnterminatedError) Error() string {
-	return fmt.Sprintf("%d:%d: %s: missing end %c",
+	return fmt.Sprintf("%d:%d-%d:%d: %s: missing end %c",
+		e.lineStart,
 		e.columnStart,
+		e.lineEnd,
 		e.columnEnd,
 		e.input[e.offsetStart:e.offsetEnd],
 		e.quote,
@@ -94,13 +142,51 @@ type Lexer struct {
 	start  int // the offset of the current token
 	pos    int // the position of the cursor in the input
 	width  int // the width of the last rune
-	column int // the column offset of the current token
-	cols   int // the number of columns (runes) decoded from the input
+	line   int // the line number of the current token
+	lines  int // the line number of the cursor
+	column int // the column offset of the current token in its line
+	cols   int // the number of columns (runes) decoded from the current line
+	last   int // the number of columns in the line before the cursor
+	offset int // the offset of input from the start of the stream
+
+	// The following fields are used when the input is read from an io.Reader.
+	// Just the runes needed for the current token are kept in input.
+	r    *bufio.Reader
+	buf  *strings.Builder
+	rerr error // the first error from r other than io.EOF
 }
 
 func NewLexer(input string) Lexer {
+	return newLexerAt(input, Position{LineStart: 1})
+}
+
+// newLexerAt returns a Lexer for input where input starts at the offset, line
+// and column of start in a larger input.
+func newLexerAt(input string, start Position) Lexer {
+	return Lexer{
+		input:  input,
+		offset: start.OffsetStart,
+		line:   start.LineStart,
+		lines:  start.LineStart,
+		column: start.ColumnStart,
+		cols:   start.ColumnStart,
+	}
+}
+
+// NewReaderLexer returns a Lexer that reads its input from r. It emits the
+// same tokens and positions as a Lexer for the same input as a string, but
+// buffers just the runes needed to scan the next token. If reading from r
+// fails with an error other than io.EOF then the error is returned from Scan().
+func NewReaderLexer(r io.Reader) Lexer {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
 	return Lexer{
-		input: input,
+		r:     br,
+		buf:   &strings.Builder{},
+		line:  1,
+		lines: 1,
 	}
 }
 
@@ -108,20 +194,38 @@ func (l *Lexer) Peek() (Token, error) {
 	start := l.start
 	pos := l.pos
 	width := l.width
+	line := l.line
+	lines := l.lines
 	column := l.column
 	cols := l.cols
+	last := l.last
+	offset := l.offset
 	// Do not reset l.err because we can return it on the next call to Scan()
 	defer func() {
-		l.start = start
-		l.pos = pos
+		// The input before start might have been discarded while scanning
+		// the next token, in which case start and pos must be moved back
+		l.start = start - (l.offset - offset)
+		l.pos = pos - (l.offset - offset)
 		l.width = width
+		l.line = line
+		l.lines = lines
 		l.column = column
 		l.cols = cols
+		l.last = last
 	}()
 	return l.Scan()
 }
 
 func (l *Lexer) Scan() (Token, error) {
+	tok, err := l.scan()
+	if l.rerr != nil {
+		l.err = l.rerr
+		return Token{}, l.err
+	}
+	return tok, err
+}
+
+func (l *Lexer) scan() (Token, error) {
 	tok := Token{}
 
 	// Do not attempt to emit more tokens if the input is invalid
@@ -129,6 +233,11 @@ func (l *Lexer) Scan() (Token, error) {
 		return tok, l.err
 	}
 
+	// Discard the input from previous tokens as it is no longer needed
+	if l.r != nil {
+		l.discard()
+	}
+
 	// Iterate over each rune in the input and either emit a token or an error
 	for r := l.next(); r != eof; r = l.next() {
 		switch {
@@ -138,21 +247,44 @@ func (l *Lexer) Scan() (Token, error) {
 		case r == '}':
 			tok = l.emit(TokenCloseBrace)
 			return tok, l.err
+		case r == '(':
+			tok = l.emit(TokenOpenParen)
+			return tok, l.err
+		case r == ')':
+			tok = l.emit(TokenCloseParen)
+			return tok, l.err
 		case r == ',':
 			tok = l.emit(TokenComma)
 			return tok, l.err
-		case r == '=' || r == '!':
+		case r == '=' || r == '!' || r == '^' || r == '*' || r == '<' || r == '>' || r == '~':
 			l.rewind()
 			tok, l.err = l.scanOperator()
 			return tok, l.err
-		case r == '"':
+		case isQuote(r):
 			l.rewind()
-			tok, l.err = l.scanQuoted()
+			tok, l.err = l.scanQuoted(r)
 			return tok, l.err
 		case r == '_' || isAlpha(r):
 			l.rewind()
 			tok, l.err = l.scanIdent()
 			return tok, l.err
+		case r == '-' || isNum(r):
+			l.rewind()
+			tok, l.err = l.scanNumber()
+			return tok, l.err
+		case r == '@':
+			l.rewind()
+			tok, l.err = l.scanReference()
+			return tok, l.err
+		case r == '$':
+			// A '$' followed by an '=' is the suffix operator rather than
+			// a variable
+			if l.accept("=") {
+				tok = l.emit(TokenOperator)
+				return tok, l.err
+			}
+			tok, l.err = l.scanVariable()
+			return tok, l.err
 		case unicode.IsSpace(r):
 			l.skip()
 		default:
@@ -160,6 +292,8 @@ func (l *Lexer) Scan() (Token, error) {
 				input:       l.input,
 				offsetStart: l.start,
 				offsetEnd:   l.pos,
+				lineStart:   l.line,
+				lineEnd:     l.lines,
 				columnStart: l.column,
 				columnEnd:   l.cols,
 			}
@@ -180,23 +314,135 @@ func (l *Lexer) scanIdent() (Token, error) {
 	return l.emit(TokenIdent), nil
 }
 
+// scanReference scans a reference to a named group of matchers such as
+// @prod_payments. The '@' must be followed by a name which, unlike an ident,
+// cannot contain ':'.
+func (l *Lexer) scanReference() (Token, error) {
+	if err := l.expect("@"); err != nil {
+		return Token{}, err
+	}
+	if r := l.next(); r != '_' && !isAlpha(r) {
+		return Token{}, InvalidInputError{
+			input:       l.input,
+			offsetStart: l.start,
+			offsetEnd:   l.pos,
+			lineStart:   l.line,
+			lineEnd:     l.lines,
+			columnStart: l.column,
+			columnEnd:   l.cols,
+		}
+	}
+	for r := l.next(); r != eof; r = l.next() {
+		if !isAlpha(r) && !isNum(r) && r != '_' {
+			l.rewind()
+			break
+		}
+	}
+	return l.emit(TokenReference), nil
+}
+
+// scanVariable scans a reference to a variable such as $cluster or
+// ${cluster} where the '$' has already been scanned. Like references, the
+// name of a variable cannot contain ':'.
+func (l *Lexer) scanVariable() (Token, error) {
+	hasBrace := l.accept("{")
+	if r := l.next(); r != '_' && !isAlpha(r) {
+		return Token{}, InvalidInputError{
+			input:       l.input,
+			offsetStart: l.start,
+			offsetEnd:   l.pos,
+			lineStart:   l.line,
+			lineEnd:     l.lines,
+			columnStart: l.column,
+			columnEnd:   l.cols,
+		}
+	}
+	for r := l.next(); r != eof; r = l.next() {
+		if !isAlpha(r) && !isNum(r) && r != '_' {
+			l.rewind()
+			break
+		}
+	}
+	if hasBrace {
+		if err := l.expect("}"); err != nil {
+			return Token{}, err
+		}
+	}
+	return l.emit(TokenVariable), nil
+}
+
+// scanNumber scans a number such as 3, -1.5, 1e6 or a version such as 1.2.3.
+// It must start with a digit or a '-' followed by a digit, but can contain
+// letters so it is possible to scan versions such as 1.0.0-rc.1+build.1.
+func (l *Lexer) scanNumber() (Token, error) {
+	l.accept("-")
+	if err := l.expect("0123456789"); err != nil {
+		return Token{}, err
+	}
+	for r := l.next(); r != eof; r = l.next() {
+		if !isAlpha(r) && !isNum(r) && !strings.ContainsRune("_.+-", r) {
+			l.rewind()
+			break
+		}
+	}
+	return l.emit(TokenNumber), nil
+}
+
 func (l *Lexer) scanOperator() (Token, error) {
-	if err := l.expect("!="); err != nil {
+	if err := l.expect("!=^*<>~"); err != nil {
 		return Token{}, err
 	}
 
-	// Rewind because we need to know if the rune was an '!' or an '='
+	// Rewind because we need to know which rune it was
 	l.rewind()
 
 	// If the first rune is an '!' then it must be followed with either an
-	// '=' or '~' to not match a string or regex
+	// '=' or '~' to not match a string or regex, '~=' to not match a string
+	// ignoring case, or the prefix, suffix or contains operator to not match
+	// those
 	if l.accept("!") {
-		if err := l.expect("=~"); err != nil {
+		// If the '!' is followed by an ident then it negates the ident, such
+		// as !exists(foo), rather than an operator
+		if r := l.next(); r == '_' || isAlpha(r) {
+			l.rewind()
+			return l.emit(TokenNot), nil
+		}
+		l.rewind()
+		if err := l.expect("=~^$*"); err != nil {
+			return Token{}, err
+		}
+		l.rewind()
+		if l.accept("=") {
+			return l.emit(TokenOperator), nil
+		}
+		if l.accept("~") {
+			l.accept("=")
+			return l.emit(TokenOperator), nil
+		}
+		l.accept("^$*")
+		if err := l.expect("="); err != nil {
 			return Token{}, err
 		}
 		return l.emit(TokenOperator), nil
 	}
 
+	// If the first rune is an '^', '*' or '~' then it must be followed with
+	// an '=' to match a prefix, contains or a string ignoring case. The suffix
+	// operator is scanned in Scan() as '$' can also start a variable
+	if l.accept("^*~") {
+		if err := l.expect("="); err != nil {
+			return Token{}, err
+		}
+		return l.emit(TokenOperator), nil
+	}
+
+	// If the first rune is an '<' or '>' then it can be followed with an
+	// optional '=' to compare numbers
+	if l.accept("<>") {
+		l.accept("=")
+		return l.emit(TokenOperator), nil
+	}
+
 	// If the first rune is an '=' then it can be followed with an optional
 	// '~' to match a regex
 	l.accept("=")
@@ -204,29 +450,34 @@ func (l *Lexer) scanOperator() (Token, error) {
 	return l.emit(TokenOperator), nil
 }
 
-func (l *Lexer) scanQuoted() (Token, error) {
-	if err := l.expect("\""); err != nil {
+// scanQuoted scans text in double quotes, single quotes or backticks. Text in
+// double and single quotes can contain escape sequences, while text in
+// backticks is raw and cannot contain backticks.
+func (l *Lexer) scanQuoted(quote rune) (Token, error) {
+	if err := l.expect(string(quote)); err != nil {
 		return Token{}, err
 	}
 	var isEscaped bool
 	for r := l.next(); r != eof; r = l.next() {
 		if isEscaped {
 			isEscaped = false
-		} else if r == '\\' {
+		} else if r == '\\' && quote != '`' {
 			isEscaped = true
-		} else if r == '"' {
+		} else if r == quote {
 			l.rewind()
 			break
 		}
 	}
-	if err := l.expect("\""); err != nil {
+	if err := l.expect(string(quote)); err != nil {
 		return Token{}, UnterminatedError{
 			input:       l.input,
 			offsetStart: l.start,
 			offsetEnd:   l.pos,
+			lineStart:   l.line,
+			lineEnd:     l.lines,
 			columnStart: l.column,
 			columnEnd:   l.cols,
-			quote:       '"',
+			quote:       quote,
 		}
 	}
 	return l.emit(TokenQuoted), nil
@@ -255,6 +506,8 @@ func (l *Lexer) expect(valid string) error {
 			input:       l.input,
 			offsetStart: l.start,
 			offsetEnd:   l.pos,
+			lineStart:   l.line,
+			lineEnd:     l.lines,
 			columnStart: l.column,
 			columnEnd:   l.cols,
 			expected:    valid,
@@ -265,6 +518,8 @@ func (l *Lexer) expect(valid string) error {
 			input:       l.input,
 			offsetStart: l.start,
 			offsetEnd:   l.pos,
+			lineStart:   l.line,
+			lineEnd:     l.lines,
 			columnStart: l.column,
 			columnEnd:   l.cols,
 			expected:    valid,
@@ -279,26 +534,82 @@ func (l *Lexer) emit(kind TokenKind) Token {
 		Kind:  kind,
 		Value: l.input[l.start:l.pos],
 		Position: Position{
-			OffsetStart: l.start,
-			OffsetEnd:   l.pos,
+			OffsetStart: l.offset + l.start,
+			OffsetEnd:   l.offset + l.pos,
+			LineStart:   l.line,
+			LineEnd:     l.lines,
 			ColumnStart: l.column,
 			ColumnEnd:   l.cols,
 		},
 	}
 	l.start = l.pos
+	l.line = l.lines
 	l.column = l.cols
 	return tok
 }
 
+// discard removes the input before the current token from the buffer.
+func (l *Lexer) discard() {
+	if l.start == 0 {
+		return
+	}
+	rest := l.input[l.start:]
+	l.buf.Reset()
+	l.buf.WriteString(rest)
+	l.input = l.buf.String()
+	l.offset += l.start
+	l.pos -= l.start
+	l.start = 0
+}
+
+// fill reads the next rune from the reader into the buffer. It returns false
+// if there are no more runes to read.
+func (l *Lexer) fill() bool {
+	if l.r == nil || l.rerr != nil {
+		return false
+	}
+	if _, err := readRune(l.r, l.buf); err != nil {
+		if err != io.EOF {
+			l.rerr = err
+		}
+		return false
+	}
+	l.input = l.buf.String()
+	return true
+}
+
+// readRune reads the next rune from r and writes it to buf. Invalid UTF-8 is
+// written to buf as it is in r rather than as the replacement character.
+func readRune(r *bufio.Reader, buf *strings.Builder) (rune, error) {
+	c, width, err := r.ReadRune()
+	if err != nil {
+		return eof, err
+	}
+	if c == utf8.RuneError && width == 1 {
+		_ = r.UnreadRune()
+		b, _ := r.ReadByte()
+		buf.WriteByte(b)
+	} else {
+		buf.WriteRune(c)
+	}
+	return c, nil
+}
+
 func (l *Lexer) next() rune {
-	if l.pos >= len(l.input) {
+	if l.pos >= len(l.input) && !l.fill() {
 		l.width = 0
 		return eof
 	}
 	r, width := utf8.DecodeRuneInString(l.input[l.pos:])
 	l.width = width
 	l.pos += width
-	l.cols++
+	if r == '\n' {
+		l.last = l.cols
+		l.lines++
+		l.cols = 0
+	} else {
+		l.cols++
+	}
 	return r
 }
 
@@ -306,11 +617,17 @@ func (l *Lexer) rewind() {
 	if l.width > 0 {
 		l.pos -= l.width
 		l.width = 0
-		l.cols--
+		if l.input[l.pos] == '\n' {
+			l.lines--
+			l.cols = l.last
+		} else {
+			l.cols--
+		}
 	}
 }
 
 func (l *Lexer) skip() {
 	l.start = l.pos
-	l.column++
+	l.line = l.lines
+	l.column = l.cols
 }
@@ -1,7 +1,11 @@
 package matchers
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +26,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -35,6 +41,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 1,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 1,
 				ColumnEnd:   2,
 			},
@@ -48,6 +56,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -61,6 +71,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -74,6 +86,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -83,6 +97,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 1,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 1,
 				ColumnEnd:   2,
 			},
@@ -96,6 +112,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -105,6 +123,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 2,
 				OffsetEnd:   3,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 2,
 				ColumnEnd:   3,
 			},
@@ -118,6 +138,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   5,
 			},
@@ -131,6 +153,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   11,
 			},
@@ -144,6 +168,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   11,
 			},
@@ -157,6 +183,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   15,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   15,
 			},
@@ -170,6 +198,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   6,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   6,
 			},
@@ -183,6 +213,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   5,
 			},
@@ -192,6 +224,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 6,
 				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 6,
 				ColumnEnd:   11,
 			},
@@ -205,6 +239,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   7,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   7,
 			},
@@ -218,6 +254,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   12,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   9,
 			},
@@ -231,6 +269,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   13,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   13,
 			},
@@ -244,10 +284,135 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   13,
+				LineStart:   1,
+				LineEnd:     2,
 				ColumnStart: 0,
-				ColumnEnd:   13,
+				ColumnEnd:   6,
+			},
+		}},
+	}, {
+		name:  "idents on separate lines",
+		input: "hello\n  world",
+		expected: []Token{{
+			Kind:  TokenIdent,
+			Value: "hello",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   5,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "world",
+			Position: Position{
+				OffsetStart: 8,
+				OffsetEnd:   13,
+				LineStart:   2,
+				LineEnd:     2,
+				ColumnStart: 2,
+				ColumnEnd:   7,
+			},
+		}},
+	}, {
+		name:  "operator at end of line",
+		input: "foo=\nbar",
+		expected: []Token{{
+			Kind:  TokenIdent,
+			Value: "foo",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   3,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   3,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: "=",
+			Position: Position{
+				OffsetStart: 3,
+				OffsetEnd:   4,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 3,
+				ColumnEnd:   4,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "bar",
+			Position: Position{
+				OffsetStart: 5,
+				OffsetEnd:   8,
+				LineStart:   2,
+				LineEnd:     2,
+				ColumnStart: 0,
+				ColumnEnd:   3,
+			},
+		}},
+	}, {
+		name:  "invalid input on second line",
+		input: "{foo=bar,\n  %}",
+		expected: []Token{{
+			Kind:  TokenOpenBrace,
+			Value: "{",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   1,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "foo",
+			Position: Position{
+				OffsetStart: 1,
+				OffsetEnd:   4,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 1,
+				ColumnEnd:   4,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: "=",
+			Position: Position{
+				OffsetStart: 4,
+				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 4,
+				ColumnEnd:   5,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "bar",
+			Position: Position{
+				OffsetStart: 5,
+				OffsetEnd:   8,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 5,
+				ColumnEnd:   8,
+			},
+		}, {
+			Kind:  TokenComma,
+			Value: ",",
+			Position: Position{
+				OffsetStart: 8,
+				OffsetEnd:   9,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 8,
+				ColumnEnd:   9,
 			},
 		}},
+		err: "2:2-2:3: %: invalid input",
 	}, {
 		name:  "quoted with tab",
 		input: "\"hello\tworld\"",
@@ -257,6 +422,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   13,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   13,
 			},
@@ -270,6 +437,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   17,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   17,
 			},
@@ -283,6 +452,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   15,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   15,
 			},
@@ -296,6 +467,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
@@ -309,6 +482,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   2,
 			},
@@ -322,6 +497,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   2,
 			},
@@ -335,6 +512,8 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   2,
 			},
@@ -342,19 +521,19 @@ func TestLexer_Scan(t *testing.T) {
 	}, {
 		name:  "unexpected $",
 		input: "$",
-		err:   "0:1: $: invalid input",
+		err:   "1:0-1:1: $: invalid input",
 	}, {
 		name:  "unexpected emoji",
 		input: "🙂",
-		err:   "0:1: 🙂: invalid input",
+		err:   "1:0-1:1: 🙂: invalid input",
 	}, {
 		name:  "unexpected unicode letter",
 		input: "Σ",
-		err:   "0:1: Σ: invalid input",
+		err:   "1:0-1:1: Σ: invalid input",
 	}, {
 		name:  "unexpected : at start of ident",
 		input: ":hello",
-		err:   "0:1: :: invalid input",
+		err:   "1:0-1:1: :: invalid input",
 	}, {
 		name:  "unexpected $ in ident",
 		input: "hello$",
@@ -364,11 +543,13 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   5,
 			},
 		}},
-		err: "5:6: $: invalid input",
+		err: "1:5-1:6: $: invalid input",
 	}, {
 		name:  "unexpected unicode letter in ident",
 		input: "helloΣ",
@@ -378,11 +559,13 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   5,
 			},
 		}},
-		err: "5:6: Σ: invalid input",
+		err: "1:5-1:6: Σ: invalid input",
 	}, {
 		name:  "unexpected emoji in ident",
 		input: "hello🙂",
@@ -392,19 +575,341 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   5,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   5,
 			},
 		}},
-		err: "5:6: 🙂: invalid input",
+		err: "1:5-1:6: 🙂: invalid input",
+	}, {
+		name:  "prefix operator",
+		input: "^=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "^=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   2,
+			},
+		}},
+	}, {
+		name:  "not prefix operator",
+		input: "!^=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "!^=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   3,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   3,
+			},
+		}},
+	}, {
+		name:  "suffix operator",
+		input: "$=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "$=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   2,
+			},
+		}},
+	}, {
+		name:  "not suffix operator",
+		input: "!$=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "!$=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   3,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   3,
+			},
+		}},
+	}, {
+		name:  "contains operator",
+		input: "*=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "*=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   2,
+			},
+		}},
+	}, {
+		name:  "not contains operator",
+		input: "!*=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "!*=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   3,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   3,
+			},
+		}},
+	}, {
+		name:  "suffix operator and variable",
+		input: "$=$foo",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "$=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   2,
+			},
+		}, {
+			Kind:  TokenVariable,
+			Value: "$foo",
+			Position: Position{
+				OffsetStart: 2,
+				OffsetEnd:   6,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 2,
+				ColumnEnd:   6,
+			},
+		}},
+	}, {
+		name:  "prefix operator without equals",
+		input: "^~",
+		err:   "1:0-1:1: ^: expected one of '='",
+	}, {
+		name:  "not prefix operator without equals",
+		input: "!^",
+		err:   "1:0-1:2: unexpected end of input, expected one of '='",
+	}, {
+		name:  "less than and greater than operators",
+		input: "< <= > >=",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "<",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   1,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: "<=",
+			Position: Position{
+				OffsetStart: 2,
+				OffsetEnd:   4,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 2,
+				ColumnEnd:   4,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: ">",
+			Position: Position{
+				OffsetStart: 5,
+				OffsetEnd:   6,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 5,
+				ColumnEnd:   6,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: ">=",
+			Position: Position{
+				OffsetStart: 7,
+				OffsetEnd:   9,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 7,
+				ColumnEnd:   9,
+			},
+		}},
+	}, {
+		name:  "numbers",
+		input: "3 -1.5 1e+6 1.0.0-rc.1+build.1",
+		expected: []Token{{
+			Kind:  TokenNumber,
+			Value: "3",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   1,
+			},
+		}, {
+			Kind:  TokenNumber,
+			Value: "-1.5",
+			Position: Position{
+				OffsetStart: 2,
+				OffsetEnd:   6,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 2,
+				ColumnEnd:   6,
+			},
+		}, {
+			Kind:  TokenNumber,
+			Value: "1e+6",
+			Position: Position{
+				OffsetStart: 7,
+				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 7,
+				ColumnEnd:   11,
+			},
+		}, {
+			Kind:  TokenNumber,
+			Value: "1.0.0-rc.1+build.1",
+			Position: Position{
+				OffsetStart: 12,
+				OffsetEnd:   30,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 12,
+				ColumnEnd:   30,
+			},
+		}},
+	}, {
+		name:  "minus without a digit",
+		input: "-a",
+		err:   "1:0-1:1: -: expected one of '0123456789'",
+	}, {
+		name:  "case-insensitive operators",
+		input: "~= !~= !~",
+		expected: []Token{{
+			Kind:  TokenOperator,
+			Value: "~=",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   2,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: "!~=",
+			Position: Position{
+				OffsetStart: 3,
+				OffsetEnd:   6,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 3,
+				ColumnEnd:   6,
+			},
+		}, {
+			Kind:  TokenOperator,
+			Value: "!~",
+			Position: Position{
+				OffsetStart: 7,
+				OffsetEnd:   9,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 7,
+				ColumnEnd:   9,
+			},
+		}},
+	}, {
+		name:  "not exists",
+		input: "!exists(foo)",
+		expected: []Token{{
+			Kind:  TokenNot,
+			Value: "!",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   1,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "exists",
+			Position: Position{
+				OffsetStart: 1,
+				OffsetEnd:   7,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 1,
+				ColumnEnd:   7,
+			},
+		}, {
+			Kind:  TokenOpenParen,
+			Value: "(",
+			Position: Position{
+				OffsetStart: 7,
+				OffsetEnd:   8,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 7,
+				ColumnEnd:   8,
+			},
+		}, {
+			Kind:  TokenIdent,
+			Value: "foo",
+			Position: Position{
+				OffsetStart: 8,
+				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 8,
+				ColumnEnd:   11,
+			},
+		}, {
+			Kind:  TokenCloseParen,
+			Value: ")",
+			Position: Position{
+				OffsetStart: 11,
+				OffsetEnd:   12,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 11,
+				ColumnEnd:   12,
+			},
+		}},
 	}, {
 		name:  "invalid operator",
 		input: "!",
-		err:   "0:1: unexpected end of input, expected one of '=~'",
+		err:   "1:0-1:1: unexpected end of input, expected one of '=~^$*'",
 	}, {
 		name:  "another invalid operator",
 		input: "~",
-		err:   "0:1: ~: invalid input",
+		err:   "1:0-1:1: unexpected end of input, expected one of '='",
 	}, {
 		name:  "unexpected $ in operator",
 		input: "=$",
@@ -414,11 +919,13 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
 		}},
-		err: "1:2: $: invalid input",
+		err: "1:1-1:2: $: invalid input",
 	}, {
 		name:  "unexpected ! after operator",
 		input: "=!",
@@ -428,11 +935,13 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   1,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   1,
 			},
 		}},
-		err: "1:2: unexpected end of input, expected one of '=~'",
+		err: "1:1-1:2: unexpected end of input, expected one of '=~^$*'",
 	}, {
 		name:  "unexpected !! after operator",
 		input: "!=!!",
@@ -442,19 +951,112 @@ func TestLexer_Scan(t *testing.T) {
 			Position: Position{
 				OffsetStart: 0,
 				OffsetEnd:   2,
+				LineStart:   1,
+				LineEnd:     1,
 				ColumnStart: 0,
 				ColumnEnd:   2,
 			},
 		}},
-		err: "2:3: !: expected one of '=~'",
+		err: "1:2-1:3: !: expected one of '=~^$*'",
+	}, {
+		name:  "reference",
+		input: "@prod_payments1",
+		expected: []Token{{
+			Kind:  TokenReference,
+			Value: "@prod_payments1",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   15,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   15,
+			},
+		}},
+	}, {
+		name:  "reference without name",
+		input: "@1",
+		err:   "1:0-1:2: @1: invalid input",
+	}, {
+		name:  "variable",
+		input: "$cluster ${cluster}",
+		expected: []Token{{
+			Kind:  TokenVariable,
+			Value: "$cluster",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   8,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   8,
+			},
+		}, {
+			Kind:  TokenVariable,
+			Value: "${cluster}",
+			Position: Position{
+				OffsetStart: 9,
+				OffsetEnd:   19,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 9,
+				ColumnEnd:   19,
+			},
+		}},
+	}, {
+		name:  "variable without name",
+		input: "${}",
+		err:   "1:0-1:3: ${}: invalid input",
+	}, {
+		name:  "variable without end brace",
+		input: "${cluster=",
+		err:   "1:0-1:9: ${cluster: expected one of '}'",
+	}, {
+		name:  "single quoted",
+		input: "'hello \\'world\\' \"🙂\"'",
+		expected: []Token{{
+			Kind:  TokenQuoted,
+			Value: "'hello \\'world\\' \"🙂\"'",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   24,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   21,
+			},
+		}},
+	}, {
+		name:  "backtick quoted",
+		input: "`[a-z]+\\d\\`",
+		expected: []Token{{
+			Kind:  TokenQuoted,
+			Value: "`[a-z]+\\d\\`",
+			Position: Position{
+				OffsetStart: 0,
+				OffsetEnd:   11,
+				LineStart:   1,
+				LineEnd:     1,
+				ColumnStart: 0,
+				ColumnEnd:   11,
+			},
+		}},
+	}, {
+		name:  "unterminated single quoted",
+		input: "'hello\\'",
+		err:   "1:0-1:8: 'hello\\': missing end '",
+	}, {
+		name:  "unterminated backtick quoted",
+		input: "`hello",
+		err:   "1:0-1:6: `hello: missing end `",
 	}, {
 		name:  "unterminated quoted",
 		input: "\"hello",
-		err:   "0:6: \"hello: missing end \"",
+		err:   "1:0-1:6: \"hello: missing end \"",
 	}, {
 		name:  "unterminated quoted with escaped quote",
 		input: "\"hello\\\"",
-		err:   "0:8: \"hello\\\": missing end \"",
+		err:   "1:0-1:8: \"hello\\\": missing end \"",
 	}}
 
 	for _, test := range tests {
@@ -488,7 +1090,7 @@ func TestLexer_ScanError(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		tok, err := l.Scan()
 		assert.Equal(t, Token{}, tok)
-		assert.EqualError(t, err, "0:6: \"hello: missing end \"")
+		assert.EqualError(t, err, "1:0-1:6: \"hello: missing end \"")
 	}
 }
 
@@ -500,6 +1102,8 @@ func TestLexer_Peek(t *testing.T) {
 		Position: Position{
 			OffsetStart: 0,
 			OffsetEnd:   5,
+			LineStart:   1,
+			LineEnd:     1,
 			ColumnStart: 0,
 			ColumnEnd:   5,
 		},
@@ -510,6 +1114,8 @@ func TestLexer_Peek(t *testing.T) {
 		Position: Position{
 			OffsetStart: 6,
 			OffsetEnd:   11,
+			LineStart:   1,
+			LineEnd:     1,
 			ColumnStart: 6,
 			ColumnEnd:   11,
 		},
@@ -547,6 +1153,112 @@ func TestLexer_PeekError(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		tok, err := l.Peek()
 		assert.Equal(t, Token{}, tok)
-		assert.EqualError(t, err, "0:6: \"hello: missing end \"")
+		assert.EqualError(t, err, "1:0-1:6: \"hello: missing end \"")
+	}
+}
+
+// This test asserts that a lexer reading from an io.Reader emits the same
+// tokens and errors as a lexer reading from a string.
+func TestReaderLexer_Scan(t *testing.T) {
+	inputs := []string{
+		"",
+		"{}",
+		" { foo = \"bar\" } ",
+		"{foo=\"bar\",bar!~\"[a-z]+\"}",
+		"{foo=~\"🙂\", bar!=baz}",
+		"{\"foo\\\"bar\"=\"\\\"baz\\\"\"}",
+		"{foo=bar}\n{bar=baz}\n",
+		"{foo=\xffbar}",
+		"=!",
+		"!=!!",
+		"{foo%=\"bar\"}",
+		"\"hello",
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			l1 := NewLexer(input)
+			l2 := NewReaderLexer(iotest.OneByteReader(strings.NewReader(input)))
+			for {
+				tok1, err1 := l1.Scan()
+				tok2, err2 := l2.Scan()
+				assert.Equal(t, tok1, tok2)
+				if err1 != nil {
+					assert.EqualError(t, err2, err1.Error())
+					break
+				}
+				assert.NoError(t, err2)
+				if IsNone(tok1) {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestReaderLexer_Peek(t *testing.T) {
+	l := NewReaderLexer(iotest.OneByteReader(strings.NewReader("hello world")))
+	expected1 := Token{
+		Kind:  TokenIdent,
+		Value: "hello",
+		Position: Position{
+			OffsetStart: 0,
+			OffsetEnd:   5,
+			LineStart:   1,
+			LineEnd:     1,
+			ColumnStart: 0,
+			ColumnEnd:   5,
+		},
+	}
+	expected2 := Token{
+		Kind:  TokenIdent,
+		Value: "world",
+		Position: Position{
+			OffsetStart: 6,
+			OffsetEnd:   11,
+			LineStart:   1,
+			LineEnd:     1,
+			ColumnStart: 6,
+			ColumnEnd:   11,
+		},
+	}
+	for i := 0; i < 10; i++ {
+		tok, err := l.Peek()
+		assert.NoError(t, err)
+		assert.Equal(t, expected1, tok)
+	}
+	tok, err := l.Scan()
+	assert.NoError(t, err)
+	assert.Equal(t, expected1, tok)
+	for i := 0; i < 10; i++ {
+		tok, err = l.Peek()
+		assert.NoError(t, err)
+		assert.Equal(t, expected2, tok)
+	}
+	tok, err = l.Scan()
+	assert.NoError(t, err)
+	assert.Equal(t, expected2, tok)
+	tok, err = l.Peek()
+	assert.NoError(t, err)
+	assert.Equal(t, Token{}, tok)
+}
+
+// This test asserts that errors from the reader are returned from Scan().
+func TestReaderLexer_ReadError(t *testing.T) {
+	readErr := errors.New("read error")
+	r := io.MultiReader(strings.NewReader("{foo=bar"), iotest.ErrReader(readErr))
+	l := NewReaderLexer(r)
+	tok, err := l.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, "{", tok.Value)
+	tok, err = l.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", tok.Value)
+	tok, err = l.Scan()
+	require.NoError(t, err)
+	assert.Equal(t, "=", tok.Value)
+	for i := 0; i < 10; i++ {
+		tok, err = l.Scan()
+		assert.Equal(t, Token{}, tok)
+		assert.Equal(t, readErr, err)
 	}
 }
@@ -0,0 +1,229 @@
+package matchers
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// Warning is a problem in a series of matchers that can be parsed, such as a
+// matcher that can never match.
+type Warning struct {
+	Position Position
+	Matcher  *Matcher
+	Message  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Position, w.Message)
+}
+
+// Lint returns warnings for the matchers in input or an error if the input
+// cannot be parsed. See Parser.Lint for the warnings.
+func Lint(input string, opts ...Option) ([]Warning, error) {
+	p := NewParser(input, opts...)
+	return p.Lint()
+}
+
+// Lint returns warnings for the matchers in the input or an error if the input
+// cannot be parsed. It warns about duplicate matchers, matchers for the same
+// label that conflict such as a="1" and a!="1", matchers that can never match,
+// matchers that match all label values, and regexes that match just one value
+// and can be written with '=' or '!='. The matchers in not groups are checked
+// separately from the matchers outside them.
+func (p *Parser) Lint() ([]Warning, error) {
+	matchers, err := p.ParseMatchers()
+	if err != nil {
+		return nil, err
+	}
+	return p.lint(matchers, nil), nil
+}
+
+// lint appends the warnings for ms to warnings.
+func (p *Parser) lint(ms Matchers, warnings []Warning) []Warning {
+	for i, m := range ms {
+		pos := p.positions[m]
+		if m.Type == MatchNot {
+			warnings = p.lint(m.Group, warnings)
+		} else if msg := lintMatcher(m); msg != "" {
+			warnings = append(warnings, Warning{Position: pos, Matcher: m, Message: msg})
+		}
+		// Just the first duplicate or conflict is reported for each matcher
+		for _, prev := range ms[:i] {
+			if prev.String() == m.String() {
+				warnings = append(warnings, Warning{
+					Position: pos,
+					Matcher:  m,
+					Message:  fmt.Sprintf("duplicate matcher %s", m),
+				})
+				break
+			}
+			if conflicts(prev, m) {
+				warnings = append(warnings, Warning{
+					Position: pos,
+					Matcher:  m,
+					Message:  fmt.Sprintf("%s conflicts with %s and can never match", m, prev),
+				})
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// lintMatcher returns a warning for m on its own or the empty string.
+func lintMatcher(m *Matcher) string {
+	switch m.Type {
+	case MatchRegexp, MatchNotRegexp:
+		re, err := syntax.Parse(m.Value, syntax.Perl)
+		if err != nil {
+			return ""
+		}
+		re = re.Simplify()
+		all, never := isMatchAll(re), neverMatches(re)
+		if m.Type == MatchNotRegexp {
+			all, never = never, all
+		}
+		if all {
+			return fmt.Sprintf("%s matches all label values", m)
+		} else if never {
+			return fmt.Sprintf("%s can never match", m)
+		}
+		if s, ok := literal(re); ok {
+			ty := MatchEqual
+			if m.Type == MatchNotRegexp {
+				ty = MatchNotEqual
+			}
+			suggested := &Matcher{Type: ty, Name: m.Name, Value: s}
+			return fmt.Sprintf("%s matches just %q, use %s", m, s, suggested)
+		}
+	case MatchPrefix, MatchSuffix, MatchContains:
+		if m.Value == "" {
+			return fmt.Sprintf("%s matches all label values", m)
+		}
+	case MatchNotPrefix, MatchNotSuffix, MatchNotContains:
+		if m.Value == "" {
+			return fmt.Sprintf("%s can never match", m)
+		}
+	}
+	return ""
+}
+
+// conflicts returns true if a and b are for the same label and no label set
+// can match both.
+func conflicts(a, b *Matcher) bool {
+	if a.Name != b.Name || a.Type == MatchNot || b.Type == MatchNot {
+		return false
+	}
+	// Order the matchers so each pair of types is checked once
+	if a.Type > b.Type {
+		a, b = b, a
+	}
+	switch {
+	case a.Type == MatchEqual && b.Type == MatchEqual:
+		return a.Value != b.Value
+	case a.Type == MatchEqual && b.Type == MatchNotEqual:
+		return a.Value == b.Value
+	case a.Type == MatchEqual && b.Type == MatchNotExists:
+		return a.Value != ""
+	case a.Type == MatchExists && b.Type == MatchNotExists:
+		return true
+	default:
+		return false
+	}
+}
+
+// isMatchAll returns true if re matches all strings. Like Alertmanager, '.'
+// is treated as matching all runes even though it does not match '\n'.
+func isMatchAll(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar:
+		return re.Sub[0].Op == syntax.OpAnyChar || re.Sub[0].Op == syntax.OpAnyCharNotNL
+	case syntax.OpCapture:
+		return isMatchAll(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if isMatchAll(sub) {
+				return true
+			}
+		}
+	case syntax.OpConcat:
+		// A concat matches all strings if one sub matches all strings and
+		// the others match just the empty string
+		hasMatchAll := false
+		for _, sub := range re.Sub {
+			if isMatchAll(sub) {
+				hasMatchAll = true
+			} else if sub.Op != syntax.OpEmptyMatch {
+				return false
+			}
+		}
+		return hasMatchAll
+	}
+	return false
+}
+
+// neverMatches returns true if re cannot match any string, such as a^ or an
+// empty character class. It follows the instructions of the compiled regex
+// from the start and returns false if it can reach a match.
+func neverMatches(re *syntax.Regexp) bool {
+	prog, err := syntax.Compile(re)
+	if err != nil {
+		return false
+	}
+	type state struct {
+		pc       uint32
+		consumed bool // a rune has been matched so ^ cannot match
+		ended    bool // $ has matched so no more runes can match
+	}
+	var (
+		seen  = make(map[state]bool)
+		queue = []state{{pc: uint32(prog.Start)}}
+	)
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		inst := prog.Inst[s.pc]
+		switch inst.Op {
+		case syntax.InstMatch:
+			return false
+		case syntax.InstFail:
+		case syntax.InstAlt, syntax.InstAltMatch:
+			queue = append(queue, state{inst.Out, s.consumed, s.ended}, state{inst.Arg, s.consumed, s.ended})
+		case syntax.InstCapture, syntax.InstNop:
+			queue = append(queue, state{inst.Out, s.consumed, s.ended})
+		case syntax.InstEmptyWidth:
+			op := syntax.EmptyOp(inst.Arg)
+			if op&syntax.EmptyBeginText != 0 && s.consumed {
+				continue
+			}
+			queue = append(queue, state{inst.Out, s.consumed, s.ended || op&syntax.EmptyEndText != 0})
+		case syntax.InstRune:
+			if !s.ended && len(inst.Rune) > 0 {
+				queue = append(queue, state{inst.Out, true, false})
+			}
+		default:
+			if !s.ended {
+				queue = append(queue, state{inst.Out, true, false})
+			}
+		}
+	}
+	return true
+}
+
+// literal returns the string that re matches if it matches just one string.
+func literal(re *syntax.Regexp) (string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return "", true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return "", false
+		}
+		return string(re.Rune), true
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		error    string
+	}{{
+		name:  "no warnings",
+		input: "{a=1,b!=2,c=~\"[a-z]+\",d!~\"x|y\",exists(e)}",
+	}, {
+		name:     "duplicate",
+		input:    "{a=1,a=\"1\"}",
+		expected: []string{"1:5-1:10: duplicate matcher a=\"1\""},
+	}, {
+		name:  "conflicting equality",
+		input: "{a=1,a!=1,b=1,b=2,c=1,!exists(c),exists(d),!exists(d)}",
+		expected: []string{
+			"1:5-1:9: a!=\"1\" conflicts with a=\"1\" and can never match",
+			"1:14-1:17: b=\"2\" conflicts with b=\"1\" and can never match",
+			"1:22-1:32: !exists(c) conflicts with c=\"1\" and can never match",
+			"1:43-1:53: !exists(d) conflicts with exists(d) and can never match",
+		},
+	}, {
+		name:  "regexes that can never match",
+		input: "{a=~\"x^\",b=~\"$x\",c=~\"[^\\\\x00-\\\\x{10FFFF}]\",d!~\".*\"}",
+		expected: []string{
+			"1:1-1:8: a=~\"x^\" can never match",
+			"1:9-1:16: b=~\"$x\" can never match",
+			"1:17-1:42: c=~\"[^\\\\x00-\\\\x{10FFFF}]\" can never match",
+			"1:43-1:50: d!~\".*\" can never match",
+		},
+	}, {
+		name:  "tautologies",
+		input: "{a=~\".*\",b=~\"(foo|.*)\",c^=\"\",d!~\"x^\"}",
+		expected: []string{
+			"1:1-1:8: a=~\".*\" matches all label values",
+			"1:9-1:22: b=~\"(foo|.*)\" matches all label values",
+			"1:23-1:28: c^=\"\" matches all label values",
+			"1:29-1:36: d!~\"x^\" matches all label values",
+		},
+	}, {
+		name:  "regexes without metacharacters",
+		input: "{a=~foo,b!~\"a\\\\.b\",c=~\"(?i)foo\"}",
+		expected: []string{
+			"1:1-1:7: a=~\"foo\" matches just \"foo\", use a=\"foo\"",
+			"1:8-1:18: b!~\"a\\\\.b\" matches just \"a.b\", use b!=\"a.b\"",
+		},
+	}, {
+		name:  "not groups are checked separately",
+		input: "{a=1,not {a=1,a=1},not {a=1,a=1}}",
+		expected: []string{
+			"1:14-1:17: duplicate matcher a=\"1\"",
+			"1:28-1:31: duplicate matcher a=\"1\"",
+			"1:19-1:32: duplicate matcher not {a=\"1\", a=\"1\"}",
+		},
+	}, {
+		name:  "invalid input",
+		input: "{a=1,a}",
+		error: "1:6-1:7: unexpected }: expected an operator such as '=', '!=', '=~' or '!~'",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warnings, err := Lint(test.input)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+				return
+			}
+			require.NoError(t, err)
+			var actual []string
+			for _, w := range warnings {
+				actual = append(actual, w.String())
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	ErrNotNumber            = errors.New("expected a number")
+	ErrNotVersion           = errors.New("expected a version")
+	ErrUnsupportedMatchType = errors.New("match type is not supported in Alertmanager")
+)
+
+// MatchType is the type of comparison in a Matcher. It has all the match
+// types in labels.MatchType and the match types that are not supported in
+// Alertmanager.
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+	MatchPrefix
+	MatchNotPrefix
+	MatchSuffix
+	MatchNotSuffix
+	MatchContains
+	MatchNotContains
+	MatchLess
+	MatchLessEqual
+	MatchGreater
+	MatchGreaterEqual
+	MatchEqualFold
+	MatchNotEqualFold
+	MatchExists
+	MatchNotExists
+	MatchNot
+)
+
+func (t MatchType) String() string {
+	switch t {
+	case MatchEqual:
+		return "="
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	case MatchPrefix:
+		return "^="
+	case MatchNotPrefix:
+		return "!^="
+	case MatchSuffix:
+		return "$="
+	case MatchNotSuffix:
+		return "!$="
+	case MatchContains:
+		return "*="
+	case MatchNotContains:
+		return "!*="
+	case MatchLess:
+		return "<"
+	case MatchLessEqual:
+		return "<="
+	case MatchGreater:
+		return ">"
+	case MatchGreaterEqual:
+		return ">="
+	case MatchEqualFold:
+		return "~="
+	case MatchNotEqualFold:
+		return "!~="
+	case MatchExists:
+		return "exists"
+	case MatchNotExists:
+		return "!exists"
+	case MatchNot:
+		return "not"
+	default:
+		panic("unknown match type")
+	}
+}
+
+// IsComparison returns true if the match type compares numbers or versions.
+func (t MatchType) IsComparison() bool {
+	return t == MatchLess || t == MatchLessEqual || t == MatchGreater || t == MatchGreaterEqual
+}
+
+// Matcher is like labels.Matcher but also supports the match types that are
+// not supported in Alertmanager. It can be converted to a labels.Matcher with
+// Labels(). Not matchers have no label name or value, and instead match a
+// label set when the matchers in Group do not all match it.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+	Group Matchers
+
+	re      *regexp.Regexp
+	num     float64  // the value as a number for comparisons
+	version *version // the value as a version for version comparisons
+}
+
+// NewMatcher returns a Matcher or an error if the match type is a regex and
+// the value is not a valid regex, or the match type is a comparison and the
+// value is not a number. Like labels.Matcher, regexes are anchored at both
+// ends.
+func NewMatcher(t MatchType, name, value string) (*Matcher, error) {
+	m := &Matcher{
+		Type:  t,
+		Name:  name,
+		Value: value,
+	}
+	if t == MatchRegexp || t == MatchNotRegexp {
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return nil, regexpError(value, err)
+		}
+		m.re = re
+	} else if t.IsComparison() {
+		num, ok := parseNumber(value)
+		if !ok {
+			return nil, ErrNotNumber
+		}
+		m.num = num
+	}
+	return m, nil
+}
+
+// regexpError returns the error for a regex value that could not be compiled
+// with the anchors added in NewMatcher and labels.NewMatcher. The value is
+// parsed again without the anchors so the error is about the value, such as
+// `[a-z` rather than `^(?:[a-z)$`. Values such as a)|(b are valid with the
+// anchors but not without them, so the value is only parsed again once it is
+// known to be invalid. It returns err if the value is valid without the
+// anchors.
+func regexpError(value string, err error) error {
+	if _, perr := syntax.Parse(value, syntax.Perl); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// NewVersionMatcher returns a Matcher that compares semantic versions such
+// as 1.2.3 rather than numbers. It returns an error if the match type is not
+// a comparison or the value is not a version. Versions can have a leading 'v'
+// and can omit the minor and patch versions.
+func NewVersionMatcher(t MatchType, name, value string) (*Matcher, error) {
+	if !t.IsComparison() {
+		return nil, fmt.Errorf("%s is not a comparison", t)
+	}
+	v, ok := parseVersion(value)
+	if !ok {
+		return nil, ErrNotVersion
+	}
+	return &Matcher{
+		Type:    t,
+		Name:    name,
+		Value:   value,
+		version: &v,
+	}, nil
+}
+
+// NewNotMatcher returns a Matcher that matches a label set when the matchers
+// in group do not all match it. If group is empty it matches no label sets.
+func NewNotMatcher(group Matchers) *Matcher {
+	return &Matcher{
+		Type:  MatchNot,
+		Group: group,
+	}
+}
+
+// parseNumber returns s as a number. It returns false if s is not a number
+// or is NaN.
+func parseNumber(s string) (float64, bool) {
+	num, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(num) {
+		return 0, false
+	}
+	return num, true
+}
+
+// Matches returns true if the matcher matches the label value s. As just the
+// value is known, exists matchers treat the empty value as a missing label
+// like Alertmanager. Use MatchesLabels to check if the label is in a label set.
+// Not matchers match label sets rather than label values, so Matches returns
+// false.
+func (m *Matcher) Matches(s string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return s == m.Value
+	case MatchNotEqual:
+		return s != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(s)
+	case MatchNotRegexp:
+		return !m.re.MatchString(s)
+	case MatchPrefix:
+		return strings.HasPrefix(s, m.Value)
+	case MatchNotPrefix:
+		return !strings.HasPrefix(s, m.Value)
+	case MatchSuffix:
+		return strings.HasSuffix(s, m.Value)
+	case MatchNotSuffix:
+		return !strings.HasSuffix(s, m.Value)
+	case MatchContains:
+		return strings.Contains(s, m.Value)
+	case MatchNotContains:
+		return !strings.Contains(s, m.Value)
+	case MatchEqualFold:
+		return strings.EqualFold(s, m.Value)
+	case MatchNotEqualFold:
+		return !strings.EqualFold(s, m.Value)
+	case MatchExists:
+		return s != ""
+	case MatchNotExists:
+		return s == ""
+	case MatchNot:
+		return false
+	case MatchLess, MatchLessEqual, MatchGreater, MatchGreaterEqual:
+		// Label values that are not numbers or versions do not match
+		c, ok := m.compare(s)
+		if !ok {
+			return false
+		}
+		switch m.Type {
+		case MatchLess:
+			return c < 0
+		case MatchLessEqual:
+			return c <= 0
+		case MatchGreater:
+			return c > 0
+		default:
+			return c >= 0
+		}
+	default:
+		panic("unknown match type")
+	}
+}
+
+// MatchesLabels returns true if the matcher matches the value of its label in
+// the label set. Unlike Matches, exists matchers check if the label is in the
+// label set, so a label with the empty value exists. For other matchers a label
+// that is not in the label set has the empty value. Not matchers return true if
+// the matchers in the group do not all match the label set.
+func (m *Matcher) MatchesLabels(lset model.LabelSet) bool {
+	value, ok := lset[model.LabelName(m.Name)]
+	switch m.Type {
+	case MatchNot:
+		return !m.Group.Matches(lset)
+	case MatchExists:
+		return ok
+	case MatchNotExists:
+		return !ok
+	default:
+		return m.Matches(string(value))
+	}
+}
+
+// compare compares the label value s to the value of the matcher. It returns
+// false if s is not a number, or not a version for version matchers.
+func (m *Matcher) compare(s string) (int, bool) {
+	if m.version != nil {
+		v, ok := parseVersion(s)
+		if !ok {
+			return 0, false
+		}
+		return v.compare(*m.version), true
+	}
+	num, ok := parseNumber(s)
+	if !ok {
+		return 0, false
+	}
+	if num < m.num {
+		return -1, true
+	} else if num > m.num {
+		return 1, true
+	}
+	return 0, true
+}
+
+// anyValue is a regex that matches any value. Unlike .* it also matches values
+// with newlines, as Alertmanager compiles regexes without the s flag.
+const anyValue = "(?s:.*)"
+
+// Labels returns the matcher as a labels.Matcher. Prefix, suffix, contains and
+// case-insensitive matchers are returned as regex matchers that match the same
+// label values. As Alertmanager does not distinguish between a missing label
+// and a label with the empty value, exists matchers are returned as matchers
+// that the label is not equal to, or is equal to, the empty value. It returns
+// an error for comparisons as these cannot be written as regexes. Not matchers
+// with one matcher in the group are returned as the opposite of that matcher,
+// such as != for =. It returns an error for other not matchers as the opposite
+// of more than one matcher needs a disjunction.
+func (m *Matcher) Labels() (*labels.Matcher, error) {
+	value := regexp.QuoteMeta(m.Value)
+	switch m.Type {
+	case MatchEqual:
+		return labels.NewMatcher(labels.MatchEqual, m.Name, m.Value)
+	case MatchNotEqual:
+		return labels.NewMatcher(labels.MatchNotEqual, m.Name, m.Value)
+	case MatchRegexp:
+		return labels.NewMatcher(labels.MatchRegexp, m.Name, m.Value)
+	case MatchNotRegexp:
+		return labels.NewMatcher(labels.MatchNotRegexp, m.Name, m.Value)
+	case MatchPrefix:
+		return labels.NewMatcher(labels.MatchRegexp, m.Name, value+anyValue)
+	case MatchNotPrefix:
+		return labels.NewMatcher(labels.MatchNotRegexp, m.Name, value+anyValue)
+	case MatchSuffix:
+		return labels.NewMatcher(labels.MatchRegexp, m.Name, anyValue+value)
+	case MatchNotSuffix:
+		return labels.NewMatcher(labels.MatchNotRegexp, m.Name, anyValue+value)
+	case MatchContains:
+		return labels.NewMatcher(labels.MatchRegexp, m.Name, anyValue+value+anyValue)
+	case MatchNotContains:
+		return labels.NewMatcher(labels.MatchNotRegexp, m.Name, anyValue+value+anyValue)
+	case MatchEqualFold:
+		return labels.NewMatcher(labels.MatchRegexp, m.Name, "(?i)"+value)
+	case MatchNotEqualFold:
+		return labels.NewMatcher(labels.MatchNotRegexp, m.Name, "(?i)"+value)
+	case MatchExists:
+		return labels.NewMatcher(labels.MatchNotEqual, m.Name, "")
+	case MatchNotExists:
+		return labels.NewMatcher(labels.MatchEqual, m.Name, "")
+	case MatchLess, MatchLessEqual, MatchGreater, MatchGreaterEqual:
+		return nil, fmt.Errorf("%s: %w", m, ErrUnsupportedMatchType)
+	case MatchNot:
+		if len(m.Group) != 1 {
+			return nil, fmt.Errorf("%s: %w", m, ErrUnsupportedMatchType)
+		}
+		lm, err := m.Group[0].Labels()
+		if err != nil {
+			return nil, err
+		}
+		return labels.NewMatcher(negate(lm.Type), lm.Name, lm.Value)
+	default:
+		return nil, fmt.Errorf("unknown match type: %d", m.Type)
+	}
+}
+
+// negate returns the match type that matches the label values that t does not.
+func negate(t labels.MatchType) labels.MatchType {
+	switch t {
+	case labels.MatchEqual:
+		return labels.MatchNotEqual
+	case labels.MatchNotEqual:
+		return labels.MatchEqual
+	case labels.MatchRegexp:
+		return labels.MatchNotRegexp
+	default:
+		return labels.MatchRegexp
+	}
+}
+
+func (m *Matcher) String() string {
+	if m.Type == MatchNot {
+		return "not " + m.Group.String()
+	}
+	if m.Type == MatchExists || m.Type == MatchNotExists {
+		return m.Type.String() + "(" + formatLabelName(m.Name) + ")"
+	}
+	return formatLabelName(m.Name) + m.Type.String() + strconv.Quote(m.Value)
+}
+
+// Matchers is a series of matchers that match a label set if all the matchers
+// match the label set.
+type Matchers []*Matcher
+
+// Matches returns true if all the matchers match the label set. Like
+// Alertmanager, a label that is not in the label set has the empty value
+// except in exists and not matchers.
+func (ms Matchers) Matches(lset model.LabelSet) bool {
+	for _, m := range ms {
+		if !m.MatchesLabels(lset) {
+			return false
+		}
+	}
+	return true
+}
+
+// Labels returns the matchers as labels.Matchers.
+func (ms Matchers) Labels() (labels.Matchers, error) {
+	if ms == nil {
+		return nil, nil
+	}
+	result := make(labels.Matchers, 0, len(ms))
+	for _, m := range ms {
+		lm, err := m.Labels()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, lm)
+	}
+	return result, nil
+}
+
+// String returns the matchers in braces. The string can be parsed with
+// ParseMatchers.
+func (ms Matchers) String() string {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, m := range ms {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(m.String())
+	}
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,392 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Matches(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  *Matcher
+		value    string
+		expected bool
+	}{{
+		name:     "equal",
+		matcher:  mustNewMatcherOfType(t, MatchEqual, "foo", "bar"),
+		value:    "bar",
+		expected: true,
+	}, {
+		name:     "regex is anchored",
+		matcher:  mustNewMatcherOfType(t, MatchRegexp, "foo", "ba"),
+		value:    "bar",
+		expected: false,
+	}, {
+		name:     "prefix",
+		matcher:  mustNewMatcherOfType(t, MatchPrefix, "foo", "ba"),
+		value:    "bar",
+		expected: true,
+	}, {
+		name:     "prefix is not a regex",
+		matcher:  mustNewMatcherOfType(t, MatchPrefix, "foo", "b."),
+		value:    "bar",
+		expected: false,
+	}, {
+		name:     "not prefix",
+		matcher:  mustNewMatcherOfType(t, MatchNotPrefix, "foo", "ba"),
+		value:    "bar",
+		expected: false,
+	}, {
+		name:     "suffix",
+		matcher:  mustNewMatcherOfType(t, MatchSuffix, "foo", "-canary"),
+		value:    "api-canary",
+		expected: true,
+	}, {
+		name:     "not suffix",
+		matcher:  mustNewMatcherOfType(t, MatchNotSuffix, "foo", "-canary"),
+		value:    "api",
+		expected: true,
+	}, {
+		name:     "contains",
+		matcher:  mustNewMatcherOfType(t, MatchContains, "foo", "timeout"),
+		value:    "connection timeout exceeded",
+		expected: true,
+	}, {
+		name:     "not contains",
+		matcher:  mustNewMatcherOfType(t, MatchNotContains, "foo", "timeout"),
+		value:    "connection timeout exceeded",
+		expected: false,
+	}, {
+		name:     "equal fold",
+		matcher:  mustNewMatcherOfType(t, MatchEqualFold, "severity", "critical"),
+		value:    "CRITICAL",
+		expected: true,
+	}, {
+		name:     "equal fold unicode",
+		matcher:  mustNewMatcherOfType(t, MatchEqualFold, "foo", "straße"),
+		value:    "STRASSE",
+		expected: false,
+	}, {
+		name:     "equal fold unicode simple folding",
+		matcher:  mustNewMatcherOfType(t, MatchEqualFold, "foo", "ΣΊΣΥΦΟΣ"),
+		value:    "σίσυφος",
+		expected: true,
+	}, {
+		name:     "equal fold is not a regex",
+		matcher:  mustNewMatcherOfType(t, MatchEqualFold, "foo", "a.c"),
+		value:    "ABC",
+		expected: false,
+	}, {
+		name:     "not equal fold",
+		matcher:  mustNewMatcherOfType(t, MatchNotEqualFold, "severity", "critical"),
+		value:    "Critical",
+		expected: false,
+	}, {
+		name:     "contains empty string",
+		matcher:  mustNewMatcherOfType(t, MatchContains, "foo", ""),
+		value:    "",
+		expected: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.matcher.Matches(test.value))
+			// The matcher must match the same value when converted to a
+			// labels.Matcher
+			m, err := test.matcher.Labels()
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, m.Matches(test.value))
+		})
+	}
+}
+
+func TestMatcher_MatchesComparison(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  *Matcher
+		value    string
+		expected bool
+	}{{
+		name:     "less than",
+		matcher:  mustNewMatcherOfType(t, MatchLess, "http_status", "500"),
+		value:    "404",
+		expected: true,
+	}, {
+		name:     "less than is numeric",
+		matcher:  mustNewMatcherOfType(t, MatchLess, "http_status", "500"),
+		value:    "1000",
+		expected: false,
+	}, {
+		name:     "less than or equal",
+		matcher:  mustNewMatcherOfType(t, MatchLessEqual, "priority", "3"),
+		value:    "3.0",
+		expected: true,
+	}, {
+		name:     "greater than",
+		matcher:  mustNewMatcherOfType(t, MatchGreater, "priority", "-1.5"),
+		value:    "-1",
+		expected: true,
+	}, {
+		name:     "greater than or equal",
+		matcher:  mustNewMatcherOfType(t, MatchGreaterEqual, "priority", "3"),
+		value:    "2",
+		expected: false,
+	}, {
+		name:     "value is not a number",
+		matcher:  mustNewMatcherOfType(t, MatchLess, "priority", "3"),
+		value:    "low",
+		expected: false,
+	}, {
+		name:     "value is empty",
+		matcher:  mustNewMatcherOfType(t, MatchLess, "priority", "3"),
+		value:    "",
+		expected: false,
+	}, {
+		name:     "value is NaN",
+		matcher:  mustNewMatcherOfType(t, MatchGreaterEqual, "priority", "3"),
+		value:    "NaN",
+		expected: false,
+	}, {
+		name:     "version less than",
+		matcher:  mustNewVersionMatcher(t, MatchLess, "version", "1.10.0"),
+		value:    "1.9.2",
+		expected: true,
+	}, {
+		name:     "version with leading v",
+		matcher:  mustNewVersionMatcher(t, MatchGreaterEqual, "version", "v2"),
+		value:    "v2.0.0",
+		expected: true,
+	}, {
+		name:     "pre-release is less than release",
+		matcher:  mustNewVersionMatcher(t, MatchLess, "version", "1.0.0"),
+		value:    "1.0.0-rc.1",
+		expected: true,
+	}, {
+		name:     "pre-release identifiers",
+		matcher:  mustNewVersionMatcher(t, MatchGreater, "version", "1.0.0-alpha.1"),
+		value:    "1.0.0-alpha.beta",
+		expected: true,
+	}, {
+		name:     "numeric pre-release identifiers",
+		matcher:  mustNewVersionMatcher(t, MatchGreater, "version", "1.0.0-rc.2"),
+		value:    "1.0.0-rc.10",
+		expected: true,
+	}, {
+		name:     "build metadata is ignored",
+		matcher:  mustNewVersionMatcher(t, MatchLessEqual, "version", "1.0.0+build.2"),
+		value:    "1.0.0+build.3",
+		expected: true,
+	}, {
+		name:     "value is not a version",
+		matcher:  mustNewVersionMatcher(t, MatchLess, "version", "1.0.0"),
+		value:    "1.0.0.0",
+		expected: false,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.matcher.Matches(test.value))
+		})
+	}
+}
+
+func TestNewMatcher_Regexp(t *testing.T) {
+	// Like labels.NewMatcher the regex is compiled with the anchors, so a)|(b
+	// is the valid regex ^(?:a)|(b)$
+	m, err := NewMatcher(MatchRegexp, "foo", "a)|(b")
+	require.NoError(t, err)
+	lm, err := labels.NewMatcher(labels.MatchRegexp, "foo", "a)|(b")
+	require.NoError(t, err)
+	for _, v := range []string{"a", "b", "ab", "axb", "c"} {
+		assert.Equal(t, lm.Matches(v), m.Matches(v), v)
+	}
+	ms, err := Parse("{foo=~\"a)|(b\"}")
+	require.NoError(t, err)
+	assert.Equal(t, labels.Matchers{lm}, ms)
+	_, err = NewMatcher(MatchRegexp, "foo", "[a-z")
+	// The error is about the value rather than the regex with the anchors
+	require.EqualError(t, err, "error parsing regexp: missing closing ]: `[a-z`")
+}
+
+func TestNewMatcher_Comparison(t *testing.T) {
+	_, err := NewMatcher(MatchLess, "priority", "high")
+	require.ErrorIs(t, err, ErrNotNumber)
+	_, err = NewMatcher(MatchLess, "priority", "NaN")
+	require.ErrorIs(t, err, ErrNotNumber)
+	_, err = NewVersionMatcher(MatchLess, "version", "1.x")
+	require.ErrorIs(t, err, ErrNotVersion)
+	_, err = NewVersionMatcher(MatchEqual, "version", "1.0.0")
+	require.EqualError(t, err, "= is not a comparison")
+	// Comparisons cannot be converted to labels.Matcher
+	m := mustNewMatcherOfType(t, MatchLess, "priority", "3")
+	_, err = m.Labels()
+	require.EqualError(t, err, "priority<\"3\": match type is not supported in Alertmanager")
+}
+
+func TestMatcher_Labels(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  *Matcher
+		expected *labels.Matcher
+	}{{
+		name:     "equal",
+		matcher:  mustNewMatcherOfType(t, MatchEqual, "foo", "bar"),
+		expected: mustNewMatcher(t, labels.MatchEqual, "foo", "bar"),
+	}, {
+		name:     "not regex",
+		matcher:  mustNewMatcherOfType(t, MatchNotRegexp, "foo", "[a-z]+"),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "[a-z]+"),
+	}, {
+		name:     "prefix",
+		matcher:  mustNewMatcherOfType(t, MatchPrefix, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchRegexp, "foo", "a\\.b(?s:.*)"),
+	}, {
+		name:     "not prefix",
+		matcher:  mustNewMatcherOfType(t, MatchNotPrefix, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "a\\.b(?s:.*)"),
+	}, {
+		name:     "suffix",
+		matcher:  mustNewMatcherOfType(t, MatchSuffix, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchRegexp, "foo", "(?s:.*)a\\.b"),
+	}, {
+		name:     "not suffix",
+		matcher:  mustNewMatcherOfType(t, MatchNotSuffix, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "(?s:.*)a\\.b"),
+	}, {
+		name:     "contains",
+		matcher:  mustNewMatcherOfType(t, MatchContains, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchRegexp, "foo", "(?s:.*)a\\.b(?s:.*)"),
+	}, {
+		name:     "not contains",
+		matcher:  mustNewMatcherOfType(t, MatchNotContains, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "(?s:.*)a\\.b(?s:.*)"),
+	}, {
+		name:     "equal fold",
+		matcher:  mustNewMatcherOfType(t, MatchEqualFold, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchRegexp, "foo", "(?i)a\\.b"),
+	}, {
+		name:     "not equal fold",
+		matcher:  mustNewMatcherOfType(t, MatchNotEqualFold, "foo", "a.b"),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "(?i)a\\.b"),
+	}, {
+		name:     "not equal",
+		matcher:  NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchEqual, "foo", "bar")}),
+		expected: mustNewMatcher(t, labels.MatchNotEqual, "foo", "bar"),
+	}, {
+		name:     "not prefix group",
+		matcher:  NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchPrefix, "foo", "a.b")}),
+		expected: mustNewMatcher(t, labels.MatchNotRegexp, "foo", "a\\.b(?s:.*)"),
+	}, {
+		name:     "not exists group",
+		matcher:  NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchExists, "foo", "")}),
+		expected: mustNewMatcher(t, labels.MatchEqual, "foo", ""),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := test.matcher.Labels()
+			require.NoError(t, err)
+			assert.EqualValues(t, test.expected, m)
+		})
+	}
+}
+
+func TestMatcher_LabelsMatchesNewlines(t *testing.T) {
+	// The labels.Matcher must match the same values as the matcher, including
+	// values with newlines which .* does not match
+	types := []MatchType{
+		MatchPrefix, MatchNotPrefix,
+		MatchSuffix, MatchNotSuffix,
+		MatchContains, MatchNotContains,
+		MatchEqualFold, MatchNotEqualFold,
+	}
+	values := []string{"a", "a\nb", "b\na", "b\na\nb", "\n", "A\nb"}
+	for _, ty := range types {
+		m := mustNewMatcherOfType(t, ty, "foo", "a")
+		lm, err := m.Labels()
+		require.NoError(t, err)
+		for _, v := range values {
+			assert.Equal(t, m.Matches(v), lm.Matches(v), "%s for %q", m, v)
+		}
+	}
+}
+
+func TestMatchers_Matches(t *testing.T) {
+	ms := Matchers{
+		mustNewMatcherOfType(t, MatchPrefix, "service", "api-"),
+		mustNewMatcherOfType(t, MatchNotSuffix, "instance", "-canary"),
+	}
+	assert.True(t, ms.Matches(model.LabelSet{"service": "api-payments", "instance": "a"}))
+	assert.True(t, ms.Matches(model.LabelSet{"service": "api-payments"}))
+	assert.False(t, ms.Matches(model.LabelSet{"service": "api-payments", "instance": "a-canary"}))
+	assert.False(t, ms.Matches(model.LabelSet{"instance": "a"}))
+}
+
+func TestMatchers_MatchesExists(t *testing.T) {
+	ms := Matchers{
+		mustNewMatcherOfType(t, MatchExists, "foo", ""),
+		mustNewMatcherOfType(t, MatchNotExists, "bar", ""),
+	}
+	assert.True(t, ms.Matches(model.LabelSet{"foo": "a"}))
+	assert.True(t, ms.Matches(model.LabelSet{"foo": ""}))
+	assert.False(t, ms.Matches(model.LabelSet{}))
+	assert.False(t, ms.Matches(model.LabelSet{"foo": "a", "bar": ""}))
+	// Matches treats the empty value as a missing label
+	assert.True(t, ms[0].Matches("a"))
+	assert.False(t, ms[0].Matches(""))
+	assert.True(t, ms[1].Matches(""))
+	assert.False(t, ms[1].Matches("a"))
+}
+
+func TestMatchers_MatchesNot(t *testing.T) {
+	ms := Matchers{
+		mustNewMatcherOfType(t, MatchEqual, "env", "prod"),
+		NewNotMatcher(Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "team", "core"),
+			mustNewMatcherOfType(t, MatchEqual, "severity", "critical"),
+		}),
+	}
+	assert.True(t, ms.Matches(model.LabelSet{"env": "prod"}))
+	assert.True(t, ms.Matches(model.LabelSet{"env": "prod", "team": "core"}))
+	assert.True(t, ms.Matches(model.LabelSet{"env": "prod", "severity": "critical"}))
+	assert.False(t, ms.Matches(model.LabelSet{"env": "prod", "team": "core", "severity": "critical"}))
+	assert.False(t, ms.Matches(model.LabelSet{"env": "dev"}))
+	// An empty group matches all label sets so its negation matches none
+	assert.False(t, NewNotMatcher(nil).MatchesLabels(model.LabelSet{}))
+}
+
+func TestMatchers_String(t *testing.T) {
+	ms := Matchers{
+		mustNewMatcherOfType(t, MatchEqual, "foo", "bar"),
+		mustNewMatcherOfType(t, MatchNotPrefix, "foo bar", "\"baz\""),
+		mustNewMatcherOfType(t, MatchContains, "qux", "🙂"),
+		mustNewMatcherOfType(t, MatchNotExists, "quux", ""),
+		NewNotMatcher(Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "a", "1"),
+			NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchRegexp, "b", "2|3")}),
+		}),
+	}
+	s := ms.String()
+	assert.Equal(t, "{foo=\"bar\", \"foo bar\"!^=\"\\\"baz\\\"\", qux*=\"🙂\", !exists(quux), "+
+		"not {a=\"1\", not {b=~\"2|3\"}}}", s)
+	// The string must parse to the same matchers
+	parsed, err := ParseMatchers(s)
+	require.NoError(t, err)
+	assert.EqualValues(t, ms, parsed)
+}
+
+func mustNewVersionMatcher(t *testing.T, op MatchType, name, value string) *Matcher {
+	m, err := NewVersionMatcher(op, name, value)
+	require.NoError(t, err)
+	return m
+}
+
+func mustNewMatcherOfType(t *testing.T, op MatchType, name, value string) *Matcher {
+	m, err := NewMatcher(op, name, value)
+	require.NoError(t, err)
+	return m
+}
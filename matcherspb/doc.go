@@ -0,0 +1,6 @@
+// Package matcherspb has the protobuf messages for matchers. The messages can
+// be converted to and from matchers with the functions in package matchers,
+// such as ToProto and FromProto.
+package matcherspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative matchers.proto
@@ -0,0 +1,470 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: matchers.proto
+
+package matcherspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MatchType is the type of comparison in a Matcher.
+type MatchType int32
+
+const (
+	MatchType_MATCH_TYPE_UNSPECIFIED    MatchType = 0
+	MatchType_MATCH_TYPE_EQUAL          MatchType = 1  // =
+	MatchType_MATCH_TYPE_NOT_EQUAL      MatchType = 2  // !=
+	MatchType_MATCH_TYPE_REGEXP         MatchType = 3  // =~
+	MatchType_MATCH_TYPE_NOT_REGEXP     MatchType = 4  // !~
+	MatchType_MATCH_TYPE_PREFIX         MatchType = 5  // ^=
+	MatchType_MATCH_TYPE_NOT_PREFIX     MatchType = 6  // !^=
+	MatchType_MATCH_TYPE_SUFFIX         MatchType = 7  // $=
+	MatchType_MATCH_TYPE_NOT_SUFFIX     MatchType = 8  // !$=
+	MatchType_MATCH_TYPE_CONTAINS       MatchType = 9  // *=
+	MatchType_MATCH_TYPE_NOT_CONTAINS   MatchType = 10 // !*=
+	MatchType_MATCH_TYPE_LESS           MatchType = 11 // <
+	MatchType_MATCH_TYPE_LESS_EQUAL     MatchType = 12 // <=
+	MatchType_MATCH_TYPE_GREATER        MatchType = 13 // >
+	MatchType_MATCH_TYPE_GREATER_EQUAL  MatchType = 14 // >=
+	MatchType_MATCH_TYPE_EQUAL_FOLD     MatchType = 15 // ~=
+	MatchType_MATCH_TYPE_NOT_EQUAL_FOLD MatchType = 16 // !~=
+	MatchType_MATCH_TYPE_EXISTS         MatchType = 17 // exists
+	MatchType_MATCH_TYPE_NOT_EXISTS     MatchType = 18 // !exists
+	MatchType_MATCH_TYPE_NOT            MatchType = 19 // not {...}
+)
+
+// Enum value maps for MatchType.
+var (
+	MatchType_name = map[int32]string{
+		0:  "MATCH_TYPE_UNSPECIFIED",
+		1:  "MATCH_TYPE_EQUAL",
+		2:  "MATCH_TYPE_NOT_EQUAL",
+		3:  "MATCH_TYPE_REGEXP",
+		4:  "MATCH_TYPE_NOT_REGEXP",
+		5:  "MATCH_TYPE_PREFIX",
+		6:  "MATCH_TYPE_NOT_PREFIX",
+		7:  "MATCH_TYPE_SUFFIX",
+		8:  "MATCH_TYPE_NOT_SUFFIX",
+		9:  "MATCH_TYPE_CONTAINS",
+		10: "MATCH_TYPE_NOT_CONTAINS",
+		11: "MATCH_TYPE_LESS",
+		12: "MATCH_TYPE_LESS_EQUAL",
+		13: "MATCH_TYPE_GREATER",
+		14: "MATCH_TYPE_GREATER_EQUAL",
+		15: "MATCH_TYPE_EQUAL_FOLD",
+		16: "MATCH_TYPE_NOT_EQUAL_FOLD",
+		17: "MATCH_TYPE_EXISTS",
+		18: "MATCH_TYPE_NOT_EXISTS",
+		19: "MATCH_TYPE_NOT",
+	}
+	MatchType_value = map[string]int32{
+		"MATCH_TYPE_UNSPECIFIED":    0,
+		"MATCH_TYPE_EQUAL":          1,
+		"MATCH_TYPE_NOT_EQUAL":      2,
+		"MATCH_TYPE_REGEXP":         3,
+		"MATCH_TYPE_NOT_REGEXP":     4,
+		"MATCH_TYPE_PREFIX":         5,
+		"MATCH_TYPE_NOT_PREFIX":     6,
+		"MATCH_TYPE_SUFFIX":         7,
+		"MATCH_TYPE_NOT_SUFFIX":     8,
+		"MATCH_TYPE_CONTAINS":       9,
+		"MATCH_TYPE_NOT_CONTAINS":   10,
+		"MATCH_TYPE_LESS":           11,
+		"MATCH_TYPE_LESS_EQUAL":     12,
+		"MATCH_TYPE_GREATER":        13,
+		"MATCH_TYPE_GREATER_EQUAL":  14,
+		"MATCH_TYPE_EQUAL_FOLD":     15,
+		"MATCH_TYPE_NOT_EQUAL_FOLD": 16,
+		"MATCH_TYPE_EXISTS":         17,
+		"MATCH_TYPE_NOT_EXISTS":     18,
+		"MATCH_TYPE_NOT":            19,
+	}
+)
+
+func (x MatchType) Enum() *MatchType {
+	p := new(MatchType)
+	*p = x
+	return p
+}
+
+func (x MatchType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MatchType) Descriptor() protoreflect.EnumDescriptor {
+	return file_matchers_proto_enumTypes[0].Descriptor()
+}
+
+func (MatchType) Type() protoreflect.EnumType {
+	return &file_matchers_proto_enumTypes[0]
+}
+
+func (x MatchType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MatchType.Descriptor instead.
+func (MatchType) EnumDescriptor() ([]byte, []int) {
+	return file_matchers_proto_rawDescGZIP(), []int{0}
+}
+
+// Matcher is a matcher such as env="prod" or a not group such as
+// not {env="prod", team="payments"}.
+type Matcher struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type MatchType `protobuf:"varint,1,opt,name=type,proto3,enum=matchers.v1.MatchType" json:"type,omitempty"`
+	// The label name. It is empty in not groups.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The value to match. It is empty in exists, !exists and not groups.
+	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// True if a comparison compares versions rather than numbers.
+	Version bool `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	// The matchers in a not group.
+	Group []*Matcher `protobuf:"bytes,5,rep,name=group,proto3" json:"group,omitempty"`
+}
+
+func (x *Matcher) Reset() {
+	*x = Matcher{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_matchers_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Matcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Matcher) ProtoMessage() {}
+
+func (x *Matcher) ProtoReflect() protoreflect.Message {
+	mi := &file_matchers_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Matcher.ProtoReflect.Descriptor instead.
+func (*Matcher) Descriptor() ([]byte, []int) {
+	return file_matchers_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Matcher) GetType() MatchType {
+	if x != nil {
+		return x.Type
+	}
+	return MatchType_MATCH_TYPE_UNSPECIFIED
+}
+
+func (x *Matcher) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Matcher) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Matcher) GetVersion() bool {
+	if x != nil {
+		return x.Version
+	}
+	return false
+}
+
+func (x *Matcher) GetGroup() []*Matcher {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// Matchers is a series of matchers that match when all the matchers match.
+type Matchers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Matchers []*Matcher `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers,omitempty"`
+}
+
+func (x *Matchers) Reset() {
+	*x = Matchers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_matchers_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Matchers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Matchers) ProtoMessage() {}
+
+func (x *Matchers) ProtoReflect() protoreflect.Message {
+	mi := &file_matchers_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Matchers.ProtoReflect.Descriptor instead.
+func (*Matchers) Descriptor() ([]byte, []int) {
+	return file_matchers_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Matchers) GetMatchers() []*Matcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
+
+// Group is a named group of matchers from a matchers file.
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Matchers []*Matcher `protobuf:"bytes,2,rep,name=matchers,proto3" json:"matchers,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_matchers_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_matchers_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_matchers_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetMatchers() []*Matcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
+
+var File_matchers_proto protoreflect.FileDescriptor
+
+var file_matchers_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0b, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x22, 0xa5, 0x01,
+	0x0a, 0x07, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x05, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x72, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x52, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x22, 0x3c, 0x0a, 0x08, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72,
+	0x73, 0x12, 0x30, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x52, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x72, 0x73, 0x22, 0x4d, 0x0a, 0x05, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x30, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x52, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x72, 0x73, 0x2a, 0x89, 0x04, 0x0a, 0x09, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x1a, 0x0a, 0x16, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10,
+	0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x51, 0x55, 0x41, 0x4c,
+	0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x45, 0x51, 0x55, 0x41, 0x4c, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11,
+	0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x47, 0x45, 0x58,
+	0x50, 0x10, 0x03, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x52, 0x45, 0x47, 0x45, 0x58, 0x50, 0x10, 0x04, 0x12, 0x15,
+	0x0a, 0x11, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x52, 0x45,
+	0x46, 0x49, 0x58, 0x10, 0x05, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x50, 0x52, 0x45, 0x46, 0x49, 0x58, 0x10, 0x06,
+	0x12, 0x15, 0x0a, 0x11, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53,
+	0x55, 0x46, 0x46, 0x49, 0x58, 0x10, 0x07, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x41, 0x54, 0x43, 0x48,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x53, 0x55, 0x46, 0x46, 0x49, 0x58,
+	0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x43, 0x4f, 0x4e, 0x54, 0x41, 0x49, 0x4e, 0x53, 0x10, 0x09, 0x12, 0x1b, 0x0a, 0x17, 0x4d,
+	0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x43, 0x4f,
+	0x4e, 0x54, 0x41, 0x49, 0x4e, 0x53, 0x10, 0x0a, 0x12, 0x13, 0x0a, 0x0f, 0x4d, 0x41, 0x54, 0x43,
+	0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4c, 0x45, 0x53, 0x53, 0x10, 0x0b, 0x12, 0x19, 0x0a,
+	0x15, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4c, 0x45, 0x53, 0x53,
+	0x5f, 0x45, 0x51, 0x55, 0x41, 0x4c, 0x10, 0x0c, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x41, 0x54, 0x43,
+	0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x47, 0x52, 0x45, 0x41, 0x54, 0x45, 0x52, 0x10, 0x0d,
+	0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x47,
+	0x52, 0x45, 0x41, 0x54, 0x45, 0x52, 0x5f, 0x45, 0x51, 0x55, 0x41, 0x4c, 0x10, 0x0e, 0x12, 0x19,
+	0x0a, 0x15, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x51, 0x55,
+	0x41, 0x4c, 0x5f, 0x46, 0x4f, 0x4c, 0x44, 0x10, 0x0f, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x41, 0x54,
+	0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x45, 0x51, 0x55, 0x41,
+	0x4c, 0x5f, 0x46, 0x4f, 0x4c, 0x44, 0x10, 0x10, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x41, 0x54, 0x43,
+	0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x11, 0x12,
+	0x19, 0x0a, 0x15, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f,
+	0x54, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x12, 0x12, 0x12, 0x0a, 0x0e, 0x4d, 0x41,
+	0x54, 0x43, 0x48, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x10, 0x13, 0x42, 0x32,
+	0x5a, 0x30, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x72, 0x6f,
+	0x62, 0x69, 0x6e, 0x73, 0x6f, 0x6e, 0x2d, 0x67, 0x72, 0x61, 0x66, 0x61, 0x6e, 0x61, 0x2f, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x2f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_matchers_proto_rawDescOnce sync.Once
+	file_matchers_proto_rawDescData = file_matchers_proto_rawDesc
+)
+
+func file_matchers_proto_rawDescGZIP() []byte {
+	file_matchers_proto_rawDescOnce.Do(func() {
+		file_matchers_proto_rawDescData = protoimpl.X.CompressGZIP(file_matchers_proto_rawDescData)
+	})
+	return file_matchers_proto_rawDescData
+}
+
+var file_matchers_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_matchers_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_matchers_proto_goTypes = []interface{}{
+	(MatchType)(0),   // 0: matchers.v1.MatchType
+	(*Matcher)(nil),  // 1: matchers.v1.Matcher
+	(*Matchers)(nil), // 2: matchers.v1.Matchers
+	(*Group)(nil),    // 3: matchers.v1.Group
+}
+var file_matchers_proto_depIdxs = []int32{
+	0, // 0: matchers.v1.Matcher.type:type_name -> matchers.v1.MatchType
+	1, // 1: matchers.v1.Matcher.group:type_name -> matchers.v1.Matcher
+	1, // 2: matchers.v1.Matchers.matchers:type_name -> matchers.v1.Matcher
+	1, // 3: matchers.v1.Group.matchers:type_name -> matchers.v1.Matcher
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_matchers_proto_init() }
+func file_matchers_proto_init() {
+	if File_matchers_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_matchers_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Matcher); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_matchers_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Matchers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_matchers_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_matchers_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_matchers_proto_goTypes,
+		DependencyIndexes: file_matchers_proto_depIdxs,
+		EnumInfos:         file_matchers_proto_enumTypes,
+		MessageInfos:      file_matchers_proto_msgTypes,
+	}.Build()
+	File_matchers_proto = out.File
+	file_matchers_proto_rawDesc = nil
+	file_matchers_proto_goTypes = nil
+	file_matchers_proto_depIdxs = nil
+}
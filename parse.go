@@ -3,9 +3,9 @@ package matchers
 import (
 	"errors"
 	"fmt"
-	"strconv"
 
 	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
 )
 
 var (
@@ -30,14 +30,126 @@ type Parser struct {
 	hasOpenParen bool
 	input        string
 	lexer        Lexer
-	matchers     labels.Matchers
+	matchers     Matchers
+	start        Position // the position of the input in a larger input
+
+	// resolve returns the matchers for a reference to a named group. If nil
+	// then references are not accepted in the input.
+	resolve func(name string, pos Position) (Matchers, error)
+
+	// vars contains the values of variables in label values. If nil then
+	// variables are not accepted in the input.
+	vars map[string]string
+
+	// hasMetricName is true if the input can start with a metric name.
+	hasMetricName bool
+
+	// hasVersions is true if comparisons compare versions rather than
+	// numbers.
+	hasVersions bool
+
+	// foldNames contains the label names where equality ignores case.
+	foldNames map[string]bool
+
+	// groups contains the not groups that are being parsed, from the
+	// outermost to the innermost group.
+	groups []notGroup
+
+	// limits are the limits on the complexity of regexes.
+	limits regexpLimits
+
+	// positions contains the position of each matcher in the input.
+	positions map[*Matcher]Position
+}
+
+// notGroup is a not group that is being parsed.
+type notGroup struct {
+	outer Matchers // the matchers outside the group
+	start Position // the position of not
+}
+
+// Option changes how the Parser parses its input.
+type Option func(p *Parser)
+
+// WithMetricName accepts a metric name before the open brace, such as
+// http_requests_total{job="api"} in PromQL. The metric name is returned as an
+// equality matcher for the label __name__. The metric name can also be the
+// whole input, such as http_requests_total. As in PromQL, not followed by an
+// open brace at the start of the input is a metric name rather than a not group.
+func WithMetricName() Option {
+	return func(p *Parser) {
+		p.hasMetricName = true
+	}
+}
+
+// WithVersions compares semantic versions such as 1.2.3 in the comparison
+// operators '<', '<=', '>' and '>=' instead of numbers.
+func WithVersions() Option {
+	return func(p *Parser) {
+		p.hasVersions = true
+	}
+}
+
+// WithCaseInsensitive ignores case in the equality operators '=' and '!=' for
+// the label names, as if the input had the operators '~=' and '!~='.
+func WithCaseInsensitive(names ...string) Option {
+	return func(p *Parser) {
+		if p.foldNames == nil {
+			p.foldNames = make(map[string]bool)
+		}
+		for _, name := range names {
+			p.foldNames[name] = true
+		}
+	}
+}
+
+// WithMaxRegexpLength limits the length of regexes in bytes.
+func WithMaxRegexpLength(n int) Option {
+	return func(p *Parser) {
+		p.limits.length = n
+	}
 }
 
-func NewParser(input string) Parser {
-	return Parser{
+// WithMaxRegexpDepth limits how deeply the groups and operators in regexes can
+// be nested. A regex such as foo has a depth of 1, and (foo|bar)+ has a depth
+// of 4.
+func WithMaxRegexpDepth(n int) Option {
+	return func(p *Parser) {
+		p.limits.depth = n
+	}
+}
+
+// WithMaxRegexpAlternations limits the number of '|' in regexes.
+func WithMaxRegexpAlternations(n int) Option {
+	return func(p *Parser) {
+		p.limits.alternations = n
+	}
+}
+
+// WithMaxRegexpRepeat limits the counts in repetitions such as a{2,100} in
+// regexes.
+func WithMaxRegexpRepeat(n int) Option {
+	return func(p *Parser) {
+		p.limits.repeat = n
+	}
+}
+
+func NewParser(input string, opts ...Option) Parser {
+	return newParserAt(input, Position{LineStart: 1}, opts...)
+}
+
+// newParserAt returns a Parser for input where input starts at the offset,
+// line and column of start in a larger input, such as a record in a file.
+func newParserAt(input string, start Position, opts ...Option) Parser {
+	p := Parser{
 		input: input,
-		lexer: NewLexer(input),
+		lexer: newLexerAt(input, start),
+		start: start,
 	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
 }
 
 // Error returns the error that caused parsing to fail.
@@ -47,8 +159,19 @@ func (p *Parser) Error() error {
 
 // Parse returns a series of matchers or an error. It can be called more than
 // once, however successive calls return the matchers and err from the first
-// call.
+// call. Matchers that are not supported in Alertmanager, such as prefix
+// matchers, are returned as equivalent regex matchers.
 func (p *Parser) Parse() (labels.Matchers, error) {
+	matchers, err := p.ParseMatchers()
+	if err != nil {
+		return nil, err
+	}
+	return matchers.Labels()
+}
+
+// ParseMatchers is like Parse but returns all match types as they are in the
+// input.
+func (p *Parser) ParseMatchers() (Matchers, error) {
 	if !p.done {
 		p.done = true
 		p.matchers, p.err = p.parse()
@@ -76,7 +199,7 @@ func (p *Parser) accept(fn func() (Token, error), kind ...TokenKind) (bool, erro
 		}
 	}
 	if tok.Kind == TokenNone {
-		return false, fmt.Errorf("0:%d: %w", len(p.input), ErrEOF)
+		return false, fmt.Errorf("%s: %w", p.inputPos(), ErrEOF)
 	}
 	return false, nil
 }
@@ -101,17 +224,20 @@ func (p *Parser) expect(fn func() (Token, error), kind ...TokenKind) (Token, err
 		}
 	}
 	if tok.Kind == TokenNone {
-		return Token{}, fmt.Errorf("0:%d: %w", len(p.input), ErrEOF)
+		return Token{}, fmt.Errorf("%s: %w", p.inputPos(), ErrEOF)
 	}
-	return Token{}, fmt.Errorf("%d:%d: unexpected %s", tok.ColumnStart, tok.ColumnEnd, tok.Value)
+	return Token{}, fmt.Errorf("%s: unexpected %s", tok.Position, tok.Value)
 }
 
-func (p *Parser) parse() (labels.Matchers, error) {
+func (p *Parser) parse() (Matchers, error) {
 	var (
 		err error
 		fn  = p.parseOpenParen
 		l   = &p.lexer
 	)
+	if p.hasMetricName {
+		fn = p.parseMetricName
+	}
 	for {
 		if fn, err = fn(l); err != nil {
 			return nil, err
@@ -124,6 +250,49 @@ func (p *Parser) parse() (labels.Matchers, error) {
 
 type parseFn func(l *Lexer) (parseFn, error)
 
+func (p *Parser) parseMetricName(l *Lexer) (parseFn, error) {
+	// Can start with an optional metric name
+	hasMetricName, err := p.accept(l.Peek, TokenIdent)
+	if err != nil {
+		if errors.Is(err, ErrEOF) {
+			return p.parseEOF, nil
+		}
+		return nil, err
+	}
+	if !hasMetricName {
+		return p.parseOpenParen, nil
+	}
+	tok, err := l.Scan()
+	if err != nil {
+		panic("Unexpected error scanning metric name")
+	}
+	// If the ident is followed by an operator then it is the label name of
+	// a matcher without braces rather than a metric name
+	next, err := l.Peek()
+	if err != nil {
+		return nil, err
+	}
+	if next.Kind == TokenOperator {
+		return p.parseMatcher(l, tok)
+	}
+	// Or if it is exists followed by an open paren then it checks if a label
+	// is in the label set
+	if next.Kind == TokenOpenParen && tok.Value == "exists" {
+		return p.parseExists(l, MatchExists, tok.Position)
+	}
+	m, err := NewMatcher(MatchEqual, model.MetricNameLabel, tok.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create matcher: %s", err)
+	}
+	p.add(m, tok.Position)
+	// The metric name can be followed by matchers in braces or the end of
+	// the input
+	if next.Kind == TokenOpenBrace {
+		return p.parseOpenParen, nil
+	}
+	return p.parseEOF, nil
+}
+
 func (p *Parser) parseOpenParen(l *Lexer) (parseFn, error) {
 	// Can start with an optional open brace
 	hasOpenParen, err := p.accept(l.Peek, TokenOpenBrace)
@@ -152,6 +321,20 @@ func (p *Parser) parseOpenParen(l *Lexer) (parseFn, error) {
 }
 
 func (p *Parser) parseCloseParen(l *Lexer) (parseFn, error) {
+	if len(p.groups) > 0 {
+		// A not group must have a close brace, and is followed by the rest
+		// of the matchers in the group outside it
+		tok, err := p.expect(l.Scan, TokenCloseBrace)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", err, ErrNoCloseBrace)
+		}
+		group := p.groups[len(p.groups)-1]
+		p.groups = p.groups[:len(p.groups)-1]
+		m := NewNotMatcher(p.matchers)
+		p.matchers = group.outer
+		p.add(m, span(group.start, tok.Position))
+		return p.parseLabelMatcherEnd, nil
+	}
 	if p.hasOpenParen {
 		// If there was an open brace there must be a matching close brace
 		if _, err := p.expect(l.Scan, TokenCloseBrace); err != nil {
@@ -160,7 +343,7 @@ func (p *Parser) parseCloseParen(l *Lexer) (parseFn, error) {
 	} else {
 		// If there was no open brace there must not be a close brace either
 		if _, err := p.expect(l.Peek, TokenCloseBrace); err == nil {
-			return nil, fmt.Errorf("0:%d: }: %w", len(p.input), ErrNoOpenBrace)
+			return nil, fmt.Errorf("%s: }: %w", p.inputPos(), ErrNoOpenBrace)
 		}
 	}
 	return p.parseEOF, nil
@@ -172,7 +355,7 @@ func (p *Parser) parseComma(l *Lexer) (parseFn, error) {
 	}
 	// The token after the comma can be another matcher, a close brace or the
 	// end of input
-	tok, err := p.expect(l.Peek, TokenCloseBrace, TokenIdent, TokenQuoted)
+	tok, err := p.expect(l.Peek, TokenCloseBrace, TokenIdent, TokenNot, TokenQuoted, TokenReference)
 	if err != nil {
 		if errors.Is(err, ErrEOF) {
 			// If this is the end of input we still need to check if the optional
@@ -196,20 +379,125 @@ func (p *Parser) parseEOF(l *Lexer) (parseFn, error) {
 
 func (p *Parser) parseLabelMatcher(l *Lexer) (parseFn, error) {
 	var (
-		err        error
-		tok        Token
-		labelName  string
-		labelValue string
-		ty         labels.MatchType
+		err error
+		tok Token
 	)
 
 	// The next token is the label name. This can either be an ident which
 	// accepts just [a-zA-Z_] or a quoted which accepts all UTF-8 characters
-	// in double quotes
-	if tok, err = p.expect(l.Scan, TokenIdent, TokenQuoted); err != nil {
+	// in double quotes, single quotes or backticks. It can also be exists or
+	// !exists to check if the label is in the label set, or not followed by
+	// matchers in braces to negate them. If references are
+	// accepted it can also be a reference to a named group of matchers
+	kinds := []TokenKind{TokenIdent, TokenNot, TokenQuoted}
+	if p.resolve != nil {
+		kinds = append(kinds, TokenReference)
+	}
+	if tok, err = p.expect(l.Scan, kinds...); err != nil {
 		return nil, fmt.Errorf("%s: %w", err, ErrNoLabelName)
 	}
-	labelName = tok.Value
+	if tok.Kind == TokenReference {
+		var group Matchers
+		if group, err = p.resolve(tok.Value[1:], tok.Position); err != nil {
+			return nil, err
+		}
+		for _, m := range group {
+			// The matchers are copied as a group can be referenced more
+			// than once
+			c := *m
+			p.add(&c, tok.Position)
+		}
+		return p.parseLabelMatcherEnd, nil
+	}
+	if tok.Kind == TokenNot {
+		return p.parseNotExists(l, tok.Position)
+	}
+	// The ident exists is a label name unless it is followed by an open paren
+	if tok.Kind == TokenIdent && tok.Value == "exists" {
+		if hasOpenParen, err := p.accept(l.Peek, TokenOpenParen); err == nil && hasOpenParen {
+			return p.parseExists(l, MatchExists, tok.Position)
+		}
+	}
+	// The ident not is a label name unless it is followed by an open brace
+	if tok.Kind == TokenIdent && tok.Value == "not" {
+		if hasOpenBrace, err := p.accept(l.Peek, TokenOpenBrace); err == nil && hasOpenBrace {
+			return p.parseNot(l, tok.Position)
+		}
+	}
+	return p.parseMatcher(l, tok)
+}
+
+// parseNot parses the open brace of a not group, where not has already been
+// scanned. The matchers in the group are parsed like the matchers outside it
+// until parseCloseParen scans its close brace.
+func (p *Parser) parseNot(l *Lexer, start Position) (parseFn, error) {
+	if _, err := p.expect(l.Scan, TokenOpenBrace); err != nil {
+		return nil, fmt.Errorf("%s: %w", err, ErrNoOpenBrace)
+	}
+	p.groups = append(p.groups, notGroup{outer: p.matchers, start: start})
+	p.matchers = nil
+	if hasCloseParen, err := p.accept(l.Peek, TokenCloseBrace); err != nil {
+		return nil, fmt.Errorf("%s: %w", err, ErrNoCloseBrace)
+	} else if hasCloseParen {
+		return p.parseCloseParen, nil
+	}
+	return p.parseLabelMatcher, nil
+}
+
+func (p *Parser) parseNotExists(l *Lexer, start Position) (parseFn, error) {
+	// The '!' must be followed by exists
+	tok, err := p.expect(l.Scan, TokenIdent)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", err, "expected exists after '!'")
+	}
+	if tok.Value != "exists" {
+		return nil, fmt.Errorf("%s: unexpected %s: %s", tok.Position, tok.Value, "expected exists after '!'")
+	}
+	return p.parseExists(l, MatchNotExists, start)
+}
+
+// parseExists parses the label name in parens after exists, where exists has
+// already been scanned at start.
+func (p *Parser) parseExists(l *Lexer, ty MatchType, start Position) (parseFn, error) {
+	if _, err := p.expect(l.Scan, TokenOpenParen); err != nil {
+		return nil, fmt.Errorf("%s: %s", err, "expected open paren")
+	}
+	tok, err := p.expect(l.Scan, TokenIdent, TokenQuoted)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err, ErrNoLabelName)
+	}
+	labelName := tok.Value
+	if tok.Kind == TokenQuoted {
+		if labelName, err = unquote(tok.Value); err != nil {
+			return nil, fmt.Errorf("%s: %s: invalid input", tok.Position, tok.Value)
+		}
+	}
+	if tok, err = p.expect(l.Scan, TokenCloseParen); err != nil {
+		return nil, fmt.Errorf("%s: %s", err, "expected close paren")
+	}
+	m, err := NewMatcher(ty, labelName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create matcher: %s", err)
+	}
+	p.add(m, span(start, tok.Position))
+	return p.parseLabelMatcherEnd, nil
+}
+
+// parseMatcher parses the operator and label value of a matcher where name is
+// the label name that has already been scanned.
+func (p *Parser) parseMatcher(l *Lexer, name Token) (parseFn, error) {
+	var (
+		err        error
+		tok        Token
+		labelName  string
+		labelValue string
+		ty         MatchType
+	)
+	if name.Kind == TokenIdent {
+		labelName = name.Value
+	} else if labelName, err = unquote(name.Value); err != nil {
+		return nil, fmt.Errorf("%s: %s: invalid input", name.Position, name.Value)
+	}
 
 	// The next token is the operator such as '=', '!=', '=~' and '!~'
 	if tok, err = p.expect(l.Scan, TokenOperator); err != nil {
@@ -218,31 +506,78 @@ func (p *Parser) parseLabelMatcher(l *Lexer) (parseFn, error) {
 	if ty, err = matchType(tok.Value); err != nil {
 		panic("Unexpected operator")
 	}
+	if p.foldNames[labelName] {
+		if ty == MatchEqual {
+			ty = MatchEqualFold
+		} else if ty == MatchNotEqual {
+			ty = MatchNotEqualFold
+		}
+	}
 
 	// The next token is the label value. This too can either be an ident
-	// which accepts just [a-zA-Z_] or a quoted which accepts all UTF-8
-	// characters in double quotes
-	if tok, err = p.expect(l.Scan, TokenIdent, TokenQuoted); err != nil {
+	// which accepts just [a-zA-Z_], a number, or a quoted which accepts all
+	// UTF-8 characters in double quotes, single quotes or backticks. If variables
+	// are accepted it can also be a variable, and variables in quoted are
+	// replaced with their values
+	kinds := []TokenKind{TokenIdent, TokenNumber, TokenQuoted}
+	if p.vars != nil {
+		kinds = append(kinds, TokenVariable)
+	}
+	if tok, err = p.expect(l.Scan, kinds...); err != nil {
 		return nil, fmt.Errorf("%s: %s", err, ErrNoLabelValue)
 	}
-	if tok.Kind == TokenIdent {
+	isRegex := ty == MatchRegexp || ty == MatchNotRegexp
+	if tok.Kind == TokenIdent || tok.Kind == TokenNumber {
 		labelValue = tok.Value
+	} else if tok.Kind == TokenVariable {
+		if labelValue, err = p.expandVariable(tok, isRegex); err != nil {
+			return nil, err
+		}
+	} else if p.vars != nil {
+		if labelValue, err = p.interpolate(tok, isRegex); err != nil {
+			return nil, err
+		}
 	} else {
-		labelValue, err = strconv.Unquote(tok.Value)
+		labelValue, err = unquote(tok.Value)
 		if err != nil {
-			return nil, fmt.Errorf("%d:%d: %s: invalid input", tok.ColumnStart, tok.ColumnEnd, tok.Value)
+			return nil, fmt.Errorf("%s: %s: invalid input", tok.Position, tok.Value)
+		}
+	}
+
+	if isRegex {
+		if err = p.limits.check(tok.Position, labelName, ty, labelValue); err != nil {
+			return nil, err
 		}
 	}
 
-	m, err := labels.NewMatcher(ty, labelName, labelValue)
+	var m *Matcher
+	if ty.IsComparison() && p.hasVersions {
+		m, err = NewVersionMatcher(ty, labelName, labelValue)
+	} else {
+		m, err = NewMatcher(ty, labelName, labelValue)
+	}
 	if err != nil {
+		if ty.IsComparison() {
+			return nil, fmt.Errorf("%s: %s: %w", tok.Position, tok.Value, err)
+		} else if isRegex {
+			return nil, newRegexpError(tok, labelValue, err)
+		}
 		return nil, fmt.Errorf("failed to create matcher: %s", err)
 	}
-	p.matchers = append(p.matchers, m)
+	p.add(m, span(name.Position, tok.Position))
 
 	return p.parseLabelMatcherEnd, nil
 }
 
+// add appends m to the matchers and records its position in the input.
+func (p *Parser) add(m *Matcher, pos Position) {
+	if p.positions == nil {
+		p.positions = make(map[*Matcher]Position)
+	}
+	p.matchers = append(p.matchers, m)
+	p.positions[m] = pos
+}
+
 func (p *Parser) parseLabelMatcherEnd(l *Lexer) (parseFn, error) {
 	tok, err := p.expect(l.Peek, TokenComma, TokenCloseBrace)
 	if err != nil {
@@ -262,21 +597,85 @@ func (p *Parser) parseLabelMatcherEnd(l *Lexer) (parseFn, error) {
 	}
 }
 
-func Parse(input string) (labels.Matchers, error) {
-	p := NewParser(input)
+func Parse(input string, opts ...Option) (labels.Matchers, error) {
+	p := NewParser(input, opts...)
 	return p.Parse()
 }
 
-func matchType(s string) (labels.MatchType, error) {
+// ParseMatchers is like Parse but returns all match types as they are in the
+// input.
+func ParseMatchers(input string, opts ...Option) (Matchers, error) {
+	p := NewParser(input, opts...)
+	return p.ParseMatchers()
+}
+
+// span returns the position from the start of start to the end of end.
+func span(start, end Position) Position {
+	return Position{
+		OffsetStart: start.OffsetStart,
+		OffsetEnd:   end.OffsetEnd,
+		LineStart:   start.LineStart,
+		LineEnd:     end.LineEnd,
+		ColumnStart: start.ColumnStart,
+		ColumnEnd:   end.ColumnEnd,
+	}
+}
+
+// inputPos returns the position of the input from its first to its last rune.
+func (p *Parser) inputPos() Position {
+	pos := Position{
+		OffsetStart: p.start.OffsetStart,
+		OffsetEnd:   p.start.OffsetStart + len(p.input),
+		LineStart:   p.start.LineStart,
+		LineEnd:     p.start.LineStart,
+		ColumnStart: p.start.ColumnStart,
+		ColumnEnd:   p.start.ColumnStart,
+	}
+	for _, r := range p.input {
+		if r == '\n' {
+			pos.LineEnd++
+			pos.ColumnEnd = 0
+		} else {
+			pos.ColumnEnd++
+		}
+	}
+	return pos
+}
+
+func matchType(s string) (MatchType, error) {
 	switch s {
 	case "=":
-		return labels.MatchEqual, nil
+		return MatchEqual, nil
 	case "!=":
-		return labels.MatchNotEqual, nil
+		return MatchNotEqual, nil
 	case "=~":
-		return labels.MatchRegexp, nil
+		return MatchRegexp, nil
 	case "!~":
-		return labels.MatchNotRegexp, nil
+		return MatchNotRegexp, nil
+	case "^=":
+		return MatchPrefix, nil
+	case "!^=":
+		return MatchNotPrefix, nil
+	case "$=":
+		return MatchSuffix, nil
+	case "!$=":
+		return MatchNotSuffix, nil
+	case "*=":
+		return MatchContains, nil
+	case "!*=":
+		return MatchNotContains, nil
+	case "<":
+		return MatchLess, nil
+	case "<=":
+		return MatchLessEqual, nil
+	case ">":
+		return MatchGreater, nil
+	case ">=":
+		return MatchGreaterEqual, nil
+	case "~=":
+		return MatchEqualFold, nil
+	case "!~=":
+		return MatchNotEqualFold, nil
 	default:
 		return -1, fmt.Errorf("unexpected operator: %s", s)
 	}
@@ -66,6 +66,18 @@ func TestParse(t *testing.T) {
 		name:     "equals with escaped backslash",
 		input:    "{foo=\"bar\\\\\"}",
 		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar\\")},
+	}, {
+		name:     "equals with quoted label name",
+		input:    "{\"foo bar\"=\"baz\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo bar", "baz")},
+	}, {
+		name:     "equals single quoted",
+		input:    "{'foo bar'='\\'baz\\' \"qux\"\\n'}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo bar", "'baz' \"qux\"\n")},
+	}, {
+		name:     "equals backtick quoted",
+		input:    "{foo=~`\\d+\\.\\d+`}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "foo", "\\d+\\.\\d+")},
 	}, {
 		name:     "not equals",
 		input:    "{foo!=\"bar\"}",
@@ -78,6 +90,18 @@ func TestParse(t *testing.T) {
 		name:     "doesn't match regex",
 		input:    "{foo!~\"[a-z]+\"}",
 		expected: labels.Matchers{mustNewMatcher(t, labels.MatchNotRegexp, "foo", "[a-z]+")},
+	}, {
+		name:     "prefix",
+		input:    "{foo^=\"a.b\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "foo", "a\\.b(?s:.*)")},
+	}, {
+		name:     "not suffix",
+		input:    "{foo!$=\"a.b\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchNotRegexp, "foo", "(?s:.*)a\\.b")},
+	}, {
+		name:     "contains",
+		input:    "{foo*=bar}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "foo", "(?s:.*)bar(?s:.*)")},
 	}, {
 		name:  "complex",
 		input: "{foo=\"bar\",bar!=\"baz\"}",
@@ -109,31 +133,50 @@ func TestParse(t *testing.T) {
 	}, {
 		name:  "open brace",
 		input: "{",
-		error: "0:1: end of input: expected close brace",
+		error: "1:0-1:1: end of input: expected close brace",
 	}, {
 		name:  "close brace",
 		input: "}",
-		error: "0:1: }: expected opening brace",
+		error: "1:0-1:1: }: expected opening brace",
 	}, {
 		name:  "no open brace",
 		input: "foo=\"bar\"}",
-		error: "0:10: }: expected opening brace",
+		error: "1:0-1:10: }: expected opening brace",
 	}, {
 		name:  "no close brace",
 		input: "{foo=\"bar\"",
-		error: "0:10: end of input: expected close brace",
+		error: "1:0-1:10: end of input: expected close brace",
 	}, {
 		name:  "invalid operator",
 		input: "{foo=:\"bar\"}",
-		error: "5:6: :: invalid input: expected label value",
+		error: "1:5-1:6: :: invalid input: expected label value",
 	}, {
 		name:  "another invalid operator",
 		input: "{foo%=\"bar\"}",
-		error: "4:5: %: invalid input: expected an operator such as '=', '!=', '=~' or '!~'",
+		error: "1:4-1:5: %: invalid input: expected an operator such as '=', '!=', '=~' or '!~'",
+	}, {
+		name:  "multiple lines",
+		input: "{\n  foo=\"bar\",\n  bar!=\"baz\"\n}",
+		expected: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "foo", "bar"),
+			mustNewMatcher(t, labels.MatchNotEqual, "bar", "baz"),
+		},
+	}, {
+		name:  "no close brace on multiple lines",
+		input: "{\n  foo=\"bar\"\n",
+		error: "1:0-3:0: end of input: expected close brace",
+	}, {
+		name:  "invalid operator on second line",
+		input: "{foo=\"bar\",\n  bar%=\"baz\"}",
+		error: "2:5-2:6: %: invalid input: expected an operator such as '=', '!=', '=~' or '!~'",
+	}, {
+		name:  "unterminated single quoted",
+		input: "{foo='bar}",
+		error: "1:5-1:10: 'bar}: missing end ': expected label value",
 	}, {
 		name:  "invalid escape sequence",
 		input: "{foo=\"bar\\w\"}",
-		error: "5:12: \"bar\\w\": invalid input",
+		error: "1:5-1:12: \"bar\\w\": invalid input",
 	}}
 
 	for _, test := range tests {
@@ -149,6 +192,257 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParse_WithMetricName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected labels.Matchers
+		error    string
+	}{{
+		name:     "no metric name",
+		input:    "{job=\"api\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "job", "api")},
+	}, {
+		name:     "metric name",
+		input:    "http_requests_total",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "__name__", "http_requests_total")},
+	}, {
+		name:     "metric name and braces",
+		input:    "http_requests_total{}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "__name__", "http_requests_total")},
+	}, {
+		name:  "metric name and matchers",
+		input: "job:http_requests:rate5m{job=\"api\",code!~\"5..\"}",
+		expected: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "__name__", "job:http_requests:rate5m"),
+			mustNewMatcher(t, labels.MatchEqual, "job", "api"),
+			mustNewMatcher(t, labels.MatchNotRegexp, "code", "5.."),
+		},
+	}, {
+		name:  "matchers without braces",
+		input: "job=\"api\",code!~\"5..\"",
+		expected: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "job", "api"),
+			mustNewMatcher(t, labels.MatchNotRegexp, "code", "5.."),
+		},
+	}, {
+		name:  "metric name and no close brace",
+		input: "http_requests_total{job=\"api\"",
+		error: "1:0-1:29: end of input: expected close brace",
+	}, {
+		name:  "metric name and matchers without braces",
+		input: "http_requests_total job=\"api\"",
+		error: "1:20-1:23: unexpected job: expected end of input",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchers, err := Parse(test.input, WithMetricName())
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, matchers)
+			}
+		})
+	}
+}
+
+func TestParseMatchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Matchers
+		error    string
+	}{{
+		name:     "no matchers",
+		input:    "{}",
+		expected: nil,
+	}, {
+		name: "all operators",
+		input: "{a=\"1\",b!=\"2\",c=~\"3\",d!~\"4\",e^=\"5\",f!^=\"6\"," +
+			"g$=\"7\",h!$=\"8\",i*=\"9\",j!*=\"10\"}",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "a", "1"),
+			mustNewMatcherOfType(t, MatchNotEqual, "b", "2"),
+			mustNewMatcherOfType(t, MatchRegexp, "c", "3"),
+			mustNewMatcherOfType(t, MatchNotRegexp, "d", "4"),
+			mustNewMatcherOfType(t, MatchPrefix, "e", "5"),
+			mustNewMatcherOfType(t, MatchNotPrefix, "f", "6"),
+			mustNewMatcherOfType(t, MatchSuffix, "g", "7"),
+			mustNewMatcherOfType(t, MatchNotSuffix, "h", "8"),
+			mustNewMatcherOfType(t, MatchContains, "i", "9"),
+			mustNewMatcherOfType(t, MatchNotContains, "j", "10"),
+		},
+	}, {
+		name:  "comparisons",
+		input: "{a<1,b<=-2.5,c>3e2,d>=\"4\"}",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchLess, "a", "1"),
+			mustNewMatcherOfType(t, MatchLessEqual, "b", "-2.5"),
+			mustNewMatcherOfType(t, MatchGreater, "c", "3e2"),
+			mustNewMatcherOfType(t, MatchGreaterEqual, "d", "4"),
+		},
+	}, {
+		name:     "number as label value",
+		input:    "{http_status=500}",
+		expected: Matchers{mustNewMatcherOfType(t, MatchEqual, "http_status", "500")},
+	}, {
+		name:  "comparison with value that is not a number",
+		input: "{priority>=3,severity<high}",
+		error: "1:22-1:26: high: expected a number",
+	}, {
+		name:  "comparison with version",
+		input: "{version>=1.2.3}",
+		error: "1:10-1:15: 1.2.3: expected a number",
+	}, {
+		name:  "case-insensitive",
+		input: "{severity~=critical,env!~=prod}",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchEqualFold, "severity", "critical"),
+			mustNewMatcherOfType(t, MatchNotEqualFold, "env", "prod"),
+		},
+	}, {
+		name:  "exists",
+		input: "{exists(foo),!exists(\"bar baz\"),exists=\"exists\"}",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchExists, "foo", ""),
+			mustNewMatcherOfType(t, MatchNotExists, "bar baz", ""),
+			mustNewMatcherOfType(t, MatchEqual, "exists", "exists"),
+		},
+	}, {
+		name:  "exists without braces",
+		input: "!exists(foo), exists(bar)",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchNotExists, "foo", ""),
+			mustNewMatcherOfType(t, MatchExists, "bar", ""),
+		},
+	}, {
+		name:  "exists without close paren",
+		input: "{exists(foo}",
+		error: "1:11-1:12: unexpected }: expected close paren",
+	}, {
+		name:  "exists without label name",
+		input: "{exists()}",
+		error: "1:8-1:9: unexpected ): expected label name",
+	}, {
+		name:  "not without exists",
+		input: "{!foo(bar)}",
+		error: "1:2-1:5: unexpected foo: expected exists after '!'",
+	}, {
+		name:  "not group",
+		input: "{env=\"prod\", not {team=\"core\", severity=\"critical\"}}",
+		expected: Matchers{
+			mustNewMatcherOfType(t, MatchEqual, "env", "prod"),
+			NewNotMatcher(Matchers{
+				mustNewMatcherOfType(t, MatchEqual, "team", "core"),
+				mustNewMatcherOfType(t, MatchEqual, "severity", "critical"),
+			}),
+		},
+	}, {
+		name:  "nested not groups without braces",
+		input: "not {a=1, not {b=2}, not {}}, c=3",
+		expected: Matchers{
+			NewNotMatcher(Matchers{
+				mustNewMatcherOfType(t, MatchEqual, "a", "1"),
+				NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchEqual, "b", "2")}),
+				NewNotMatcher(nil),
+			}),
+			mustNewMatcherOfType(t, MatchEqual, "c", "3"),
+		},
+	}, {
+		name:     "not as label name",
+		input:    "{not=\"group\"}",
+		expected: Matchers{mustNewMatcherOfType(t, MatchEqual, "not", "group")},
+	}, {
+		name:  "not group without close brace",
+		input: "{not {a=1}",
+		error: "1:0-1:10: end of input: expected close brace",
+	}, {
+		name:  "not group with trailing comma",
+		input: "{not {a=1,}}",
+		expected: Matchers{
+			NewNotMatcher(Matchers{mustNewMatcherOfType(t, MatchEqual, "a", "1")}),
+		},
+	}, {
+		name:  "prefix without equals",
+		input: "{foo^bar}",
+		error: "1:4-1:5: ^: expected one of '=': expected an operator such as '=', '!=', '=~' or '!~'",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchers, err := ParseMatchers(test.input)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, matchers)
+			}
+		})
+	}
+}
+
+func TestParseMatchers_WithVersions(t *testing.T) {
+	matchers, err := ParseMatchers("{version>=1.2.3,version<\"v2.0.0-rc.1\",major=1}", WithVersions())
+	require.NoError(t, err)
+	assert.EqualValues(t, Matchers{
+		mustNewVersionMatcher(t, MatchGreaterEqual, "version", "1.2.3"),
+		mustNewVersionMatcher(t, MatchLess, "version", "v2.0.0-rc.1"),
+		mustNewMatcherOfType(t, MatchEqual, "major", "1"),
+	}, matchers)
+	_, err = ParseMatchers("{version>=1.2.x}", WithVersions())
+	require.EqualError(t, err, "1:10-1:15: 1.2.x: expected a version")
+}
+
+func TestParse_Exists(t *testing.T) {
+	matchers, err := Parse("{exists(foo),!exists(bar)}")
+	require.NoError(t, err)
+	assert.EqualValues(t, labels.Matchers{
+		mustNewMatcher(t, labels.MatchNotEqual, "foo", ""),
+		mustNewMatcher(t, labels.MatchEqual, "bar", ""),
+	}, matchers)
+	matchers, err = Parse("exists(foo)", WithMetricName())
+	require.NoError(t, err)
+	assert.EqualValues(t, labels.Matchers{mustNewMatcher(t, labels.MatchNotEqual, "foo", "")}, matchers)
+}
+
+func TestParse_Not(t *testing.T) {
+	matchers, err := Parse("{env=prod,not {team=core}}")
+	require.NoError(t, err)
+	assert.EqualValues(t, labels.Matchers{
+		mustNewMatcher(t, labels.MatchEqual, "env", "prod"),
+		mustNewMatcher(t, labels.MatchNotEqual, "team", "core"),
+	}, matchers)
+	_, err = Parse("{env=prod,not {team=core,severity=critical}}")
+	require.EqualError(t, err, "not {team=\"core\", severity=\"critical\"}: match type is not supported in Alertmanager")
+	// With a metric name not at the start of the input is a metric name
+	matchers, err = Parse("not {team=core}", WithMetricName())
+	require.NoError(t, err)
+	assert.EqualValues(t, labels.Matchers{
+		mustNewMatcher(t, labels.MatchEqual, "__name__", "not"),
+		mustNewMatcher(t, labels.MatchEqual, "team", "core"),
+	}, matchers)
+}
+
+func TestParse_Comparison(t *testing.T) {
+	_, err := Parse("{priority>=3}")
+	require.EqualError(t, err, "priority>=\"3\": match type is not supported in Alertmanager")
+}
+
+func TestParseMatchers_WithCaseInsensitive(t *testing.T) {
+	matchers, err := ParseMatchers("{severity=critical,env!=prod,team=core,severity~=warning,env=~\"prod\"}",
+		WithCaseInsensitive("severity", "env"))
+	require.NoError(t, err)
+	assert.EqualValues(t, Matchers{
+		mustNewMatcherOfType(t, MatchEqualFold, "severity", "critical"),
+		mustNewMatcherOfType(t, MatchNotEqualFold, "env", "prod"),
+		mustNewMatcherOfType(t, MatchEqual, "team", "core"),
+		mustNewMatcherOfType(t, MatchEqualFold, "severity", "warning"),
+		mustNewMatcherOfType(t, MatchRegexp, "env", "prod"),
+	}, matchers)
+}
+
 func mustNewMatcher(t *testing.T, op labels.MatchType, name, value string) *labels.Matcher {
 	m, err := labels.NewMatcher(op, name, value)
 	require.NoError(t, err)
@@ -0,0 +1,166 @@
+package matchers
+
+import (
+	"fmt"
+
+	"github.com/grobinson-grafana/matchers/matcherspb"
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// protoMatchTypes are the match types in protobuf messages for each match
+// type.
+var protoMatchTypes = map[MatchType]matcherspb.MatchType{
+	MatchEqual:        matcherspb.MatchType_MATCH_TYPE_EQUAL,
+	MatchNotEqual:     matcherspb.MatchType_MATCH_TYPE_NOT_EQUAL,
+	MatchRegexp:       matcherspb.MatchType_MATCH_TYPE_REGEXP,
+	MatchNotRegexp:    matcherspb.MatchType_MATCH_TYPE_NOT_REGEXP,
+	MatchPrefix:       matcherspb.MatchType_MATCH_TYPE_PREFIX,
+	MatchNotPrefix:    matcherspb.MatchType_MATCH_TYPE_NOT_PREFIX,
+	MatchSuffix:       matcherspb.MatchType_MATCH_TYPE_SUFFIX,
+	MatchNotSuffix:    matcherspb.MatchType_MATCH_TYPE_NOT_SUFFIX,
+	MatchContains:     matcherspb.MatchType_MATCH_TYPE_CONTAINS,
+	MatchNotContains:  matcherspb.MatchType_MATCH_TYPE_NOT_CONTAINS,
+	MatchLess:         matcherspb.MatchType_MATCH_TYPE_LESS,
+	MatchLessEqual:    matcherspb.MatchType_MATCH_TYPE_LESS_EQUAL,
+	MatchGreater:      matcherspb.MatchType_MATCH_TYPE_GREATER,
+	MatchGreaterEqual: matcherspb.MatchType_MATCH_TYPE_GREATER_EQUAL,
+	MatchEqualFold:    matcherspb.MatchType_MATCH_TYPE_EQUAL_FOLD,
+	MatchNotEqualFold: matcherspb.MatchType_MATCH_TYPE_NOT_EQUAL_FOLD,
+	MatchExists:       matcherspb.MatchType_MATCH_TYPE_EXISTS,
+	MatchNotExists:    matcherspb.MatchType_MATCH_TYPE_NOT_EXISTS,
+	MatchNot:          matcherspb.MatchType_MATCH_TYPE_NOT,
+}
+
+// ToProto returns the matchers as a protobuf message. Not matchers have the
+// matchers in the group.
+func ToProto(ms Matchers) *matcherspb.Matchers {
+	return &matcherspb.Matchers{Matchers: toProtoMatchers(ms)}
+}
+
+func toProtoMatchers(ms Matchers) []*matcherspb.Matcher {
+	if ms == nil {
+		return nil
+	}
+	result := make([]*matcherspb.Matcher, 0, len(ms))
+	for _, m := range ms {
+		result = append(result, &matcherspb.Matcher{
+			Type:    protoMatchTypes[m.Type],
+			Name:    m.Name,
+			Value:   m.Value,
+			Version: m.version != nil,
+			Group:   toProtoMatchers(m.Group),
+		})
+	}
+	return result
+}
+
+// FromProto returns the matchers in a protobuf message. It returns an error
+// if a matcher has an unknown match type or the value is not valid for the
+// match type, such as an invalid regex.
+func FromProto(pb *matcherspb.Matchers) (Matchers, error) {
+	return fromProtoMatchers(pb.GetMatchers())
+}
+
+func fromProtoMatchers(pbs []*matcherspb.Matcher) (Matchers, error) {
+	if pbs == nil {
+		return nil, nil
+	}
+	result := make(Matchers, 0, len(pbs))
+	for _, pb := range pbs {
+		m, err := fromProtoMatcher(pb)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+func fromProtoMatcher(pb *matcherspb.Matcher) (*Matcher, error) {
+	ty, err := fromProtoMatchType(pb.GetType())
+	if err != nil {
+		return nil, err
+	}
+	if ty == MatchNot {
+		group, err := fromProtoMatchers(pb.GetGroup())
+		if err != nil {
+			return nil, err
+		}
+		return NewNotMatcher(group), nil
+	}
+	var m *Matcher
+	if pb.GetVersion() {
+		m, err = NewVersionMatcher(ty, pb.GetName(), pb.GetValue())
+	} else {
+		m, err = NewMatcher(ty, pb.GetName(), pb.GetValue())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid matcher %s: %w", &Matcher{Type: ty, Name: pb.GetName(), Value: pb.GetValue()}, err)
+	}
+	return m, nil
+}
+
+// fromProtoMatchType returns the match type for the match type in a protobuf
+// message.
+func fromProtoMatchType(t matcherspb.MatchType) (MatchType, error) {
+	for ty, pbType := range protoMatchTypes {
+		if pbType == t {
+			return ty, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown match type %s", t)
+}
+
+// LabelsToProto returns matchers such as those returned from Parse as a
+// protobuf message. It returns an error if a matcher has an unknown match
+// type.
+func LabelsToProto(ms labels.Matchers) (*matcherspb.Matchers, error) {
+	result := make([]*matcherspb.Matcher, 0, len(ms))
+	for _, m := range ms {
+		pb := matcherspb.Matcher{Name: m.Name, Value: m.Value}
+		switch m.Type {
+		case labels.MatchEqual:
+			pb.Type = matcherspb.MatchType_MATCH_TYPE_EQUAL
+		case labels.MatchNotEqual:
+			pb.Type = matcherspb.MatchType_MATCH_TYPE_NOT_EQUAL
+		case labels.MatchRegexp:
+			pb.Type = matcherspb.MatchType_MATCH_TYPE_REGEXP
+		case labels.MatchNotRegexp:
+			pb.Type = matcherspb.MatchType_MATCH_TYPE_NOT_REGEXP
+		default:
+			return nil, fmt.Errorf("unknown match type: %s", m.Type)
+		}
+		result = append(result, &pb)
+	}
+	return &matcherspb.Matchers{Matchers: result}, nil
+}
+
+// LabelsFromProto returns the matchers in a protobuf message as
+// labels.Matchers. It returns an error if a matcher is not valid or is not
+// supported in Alertmanager.
+func LabelsFromProto(pb *matcherspb.Matchers) (labels.Matchers, error) {
+	ms, err := FromProto(pb)
+	if err != nil {
+		return nil, err
+	}
+	return ms.Labels()
+}
+
+// GroupToProto returns the group as a protobuf message.
+func GroupToProto(g Group) (*matcherspb.Group, error) {
+	ms, err := LabelsToProto(g.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	return &matcherspb.Group{Name: g.Name, Matchers: ms.Matchers}, nil
+}
+
+// GroupFromProto returns the group in a protobuf message. The group has no
+// position.
+func GroupFromProto(pb *matcherspb.Group) (Group, error) {
+	ms, err := LabelsFromProto(&matcherspb.Matchers{Matchers: pb.GetMatchers()})
+	if err != nil {
+		return Group{}, fmt.Errorf("group %s: %w", pb.GetName(), err)
+	}
+	return Group{Name: pb.GetName(), Matchers: ms}, nil
+}
@@ -0,0 +1,160 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/grobinson-grafana/matchers/matcherspb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProto(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{{
+		name:  "equality and regexes",
+		input: "{a=\"1\", b!=\"2\", c=~\"[a-z]+\", d!~\"5\"}",
+	}, {
+		name:  "prefix, suffix and contains",
+		input: "{a^=\"1\", b!^=\"2\", c$=\"3\", d!$=\"4\", e*=\"5\", f!*=\"6\"}",
+	}, {
+		name:  "comparisons",
+		input: "{a<\"1\", b<=\"2\", c>\"3\", d>=\"4.5\"}",
+	}, {
+		name:  "version comparisons",
+		input: "{version>=\"v1.2.3\", version<\"2\"}",
+		opts:  []Option{WithVersions()},
+	}, {
+		name:  "case-insensitive, exists and not exists",
+		input: "{a~=\"Prod\", b!~=\"Dev\", exists(c), !exists(d)}",
+	}, {
+		name:  "not groups",
+		input: "{env=\"prod\", not {team=\"payments\", not {service=~\"api.*\"}}, not {}}",
+	}, {
+		// Like in Alertmanager the regex is ^(?:a)|(b)$ which is valid
+		name:  "regex that is valid only with the anchors",
+		input: "{foo=~\"a)|(b\"}",
+	}, {
+		name:  "no matchers",
+		input: "{}",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms, err := ParseMatchers(test.input, test.opts...)
+			require.NoError(t, err)
+			b, err := proto.Marshal(ToProto(ms))
+			require.NoError(t, err)
+			var pb matcherspb.Matchers
+			require.NoError(t, proto.Unmarshal(b, &pb))
+			result, err := FromProto(&pb)
+			require.NoError(t, err)
+			assert.Equal(t, ms.String(), result.String())
+			// The matchers must have the same regexes, numbers and versions
+			assert.Equal(t, ms, result)
+		})
+	}
+}
+
+func TestFromProto_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *matcherspb.Matchers
+		error string
+	}{{
+		name: "unspecified match type",
+		input: &matcherspb.Matchers{Matchers: []*matcherspb.Matcher{
+			{Name: "a", Value: "1"},
+		}},
+		error: "unknown match type MATCH_TYPE_UNSPECIFIED",
+	}, {
+		name: "unknown match type",
+		input: &matcherspb.Matchers{Matchers: []*matcherspb.Matcher{
+			{Type: 100, Name: "a", Value: "1"},
+		}},
+		error: "unknown match type 100",
+	}, {
+		name: "invalid regex",
+		input: &matcherspb.Matchers{Matchers: []*matcherspb.Matcher{
+			{Type: matcherspb.MatchType_MATCH_TYPE_REGEXP, Name: "a", Value: "("},
+		}},
+		error: "invalid matcher a=~\"(\": error parsing regexp: missing closing ): `(`",
+	}, {
+		name: "invalid number",
+		input: &matcherspb.Matchers{Matchers: []*matcherspb.Matcher{
+			{Type: matcherspb.MatchType_MATCH_TYPE_LESS, Name: "a", Value: "b"},
+		}},
+		error: "invalid matcher a<\"b\": expected a number",
+	}, {
+		name: "invalid matcher in a not group",
+		input: &matcherspb.Matchers{Matchers: []*matcherspb.Matcher{{
+			Type: matcherspb.MatchType_MATCH_TYPE_NOT,
+			Group: []*matcherspb.Matcher{
+				{Type: matcherspb.MatchType_MATCH_TYPE_GREATER, Name: "a", Value: "v1", Version: true},
+				{Type: matcherspb.MatchType_MATCH_TYPE_GREATER, Name: "b", Value: "x", Version: true},
+			},
+		}}},
+		error: "invalid matcher b>\"x\": expected a version",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromProto(test.input)
+			require.EqualError(t, err, test.error)
+		})
+	}
+}
+
+func TestLabelsProto(t *testing.T) {
+	ms, err := Parse("{a=1,b!=2,c=~\"3|4\",d!~\"5\"}")
+	require.NoError(t, err)
+	pb, err := LabelsToProto(ms)
+	require.NoError(t, err)
+	assert.Equal(t, []matcherspb.MatchType{
+		matcherspb.MatchType_MATCH_TYPE_EQUAL,
+		matcherspb.MatchType_MATCH_TYPE_NOT_EQUAL,
+		matcherspb.MatchType_MATCH_TYPE_REGEXP,
+		matcherspb.MatchType_MATCH_TYPE_NOT_REGEXP,
+	}, []matcherspb.MatchType{
+		pb.Matchers[0].Type,
+		pb.Matchers[1].Type,
+		pb.Matchers[2].Type,
+		pb.Matchers[3].Type,
+	})
+	result, err := LabelsFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, ms, result)
+
+	ms, err = Parse("{foo=~\"a)|(b\"}")
+	require.NoError(t, err)
+	pb, err = LabelsToProto(ms)
+	require.NoError(t, err)
+	result, err = LabelsFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, ms, result)
+
+	// Matchers that are not supported in Alertmanager cannot be converted
+	_, err = LabelsFromProto(&matcherspb.Matchers{Matchers: []*matcherspb.Matcher{
+		{Type: matcherspb.MatchType_MATCH_TYPE_LESS, Name: "a", Value: "1"},
+	}})
+	require.EqualError(t, err, "a<\"1\": match type is not supported in Alertmanager")
+}
+
+func TestGroupProto(t *testing.T) {
+	groups, err := ParseGroups("prod: {env=\"prod\"}\nprod_payments: {@prod, team=\"payments\"}\n")
+	require.NoError(t, err)
+	g := groups["prod_payments"]
+	pb, err := GroupToProto(g)
+	require.NoError(t, err)
+	b, err := proto.Marshal(pb)
+	require.NoError(t, err)
+	var decoded matcherspb.Group
+	require.NoError(t, proto.Unmarshal(b, &decoded))
+	result, err := GroupFromProto(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, "prod_payments", result.Name)
+	assert.Equal(t, g.Matchers, result.Matchers)
+}
@@ -0,0 +1,260 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RegexpError is returned when the label value of a regex matcher is not a
+// valid regex. Position is the position of the invalid part of the regex in
+// the input, such as a character class without its closing bracket, or the
+// position of the label value if the invalid part cannot be found.
+type RegexpError struct {
+	Position Position
+	Expr     string // the invalid part of the regex
+	Err      error
+}
+
+func (e RegexpError) Error() string {
+	msg := e.Err.Error()
+	var serr *syntax.Error
+	if errors.As(e.Err, &serr) {
+		msg = serr.Code.String()
+	}
+	return fmt.Sprintf("%s: %s: invalid regex: %s", e.Position, e.Expr, msg)
+}
+
+func (e RegexpError) Unwrap() error {
+	return e.Err
+}
+
+// newRegexpError returns a RegexpError for the invalid regex value in tok,
+// where err is the error from NewMatcher. If value is not the unquoted text of
+// tok, such as when tok is a variable, the error has the position of tok.
+func newRegexpError(tok Token, value string, err error) error {
+	result := RegexpError{Position: tok.Position, Expr: tok.Value, Err: err}
+	var serr *syntax.Error
+	if !errors.As(err, &serr) {
+		return result
+	}
+	// For missing brackets and parens the invalid part is the rest of the
+	// regex, which can also be found earlier in the regex such as [a-z in
+	// [a-z]+x[a-z, so it is looked for at the end first. Other invalid parts
+	// are the first that are found as parsing stops at the first error
+	i := len(value) - len(serr.Expr)
+	if !strings.HasSuffix(value, serr.Expr) {
+		if i = strings.Index(value, serr.Expr); i < 0 {
+			return result
+		}
+	}
+	if pos, ok := valuePos(tok, value, i, i+len(serr.Expr)); ok {
+		result.Position = pos
+		result.Expr = serr.Expr
+	}
+	return result
+}
+
+// valuePos returns the position in the input of the text from the ith to the
+// jth byte of value, where value is the unquoted text of tok. It returns false
+// if value is not the unquoted text of tok.
+func valuePos(tok Token, value string, i, j int) (Position, bool) {
+	var (
+		quote byte
+		raw   = tok.Value
+		pos   = tok.Position
+		start Position
+	)
+	switch tok.Kind {
+	case TokenQuoted:
+		if s, err := unquote(raw); err != nil || s != value {
+			return Position{}, false
+		}
+		quote = raw[0]
+		raw = raw[1 : len(raw)-1]
+		pos.OffsetStart++
+		pos.ColumnStart++
+	case TokenVariable:
+		return Position{}, false
+	default:
+		if raw != value {
+			return Position{}, false
+		}
+	}
+	// u is the offset in value of the text in raw from k
+	for k, u := 0, 0; ; {
+		if u == i {
+			start = pos
+		}
+		if u == j {
+			return span(start, Position{
+				OffsetEnd: pos.OffsetStart,
+				LineEnd:   pos.LineStart,
+				ColumnEnd: pos.ColumnStart,
+			}), true
+		}
+		if k >= len(raw) || u > len(value) {
+			return Position{}, false
+		}
+		var n, w int // the bytes in raw and value
+		if quote == '"' || quote == '\'' {
+			r, multibyte, tail, err := strconv.UnquoteChar(raw[k:], quote)
+			if err != nil {
+				return Position{}, false
+			}
+			n, w = len(raw[k:])-len(tail), utf8.RuneLen(r)
+			if quote == '"' && !multibyte {
+				w = 1
+			}
+		} else {
+			_, n = utf8.DecodeRuneInString(raw[k:])
+			w = n
+		}
+		for _, r := range raw[k : k+n] {
+			if r == '\n' {
+				pos.LineStart++
+				pos.ColumnStart = 0
+			} else {
+				pos.ColumnStart++
+			}
+		}
+		pos.OffsetStart += n
+		k, u = k+n, u+w
+	}
+}
+
+// RegexpLimitError is returned when a regex exceeds one of the limits such as
+// WithMaxRegexpLength. Position is the position of the label value.
+type RegexpLimitError struct {
+	Position Position
+	Matcher  *Matcher
+	Limit    string // the name of the limit such as length
+	Max      int    // the limit
+	Actual   int    // the value in the regex that exceeded the limit
+}
+
+func (e RegexpLimitError) Error() string {
+	return fmt.Sprintf("%s: %s: regex %s of %d exceeds the limit of %d",
+		e.Position, e.Matcher, e.Limit, e.Actual, e.Max)
+}
+
+// regexpLimits are the limits on the complexity of regexes. Limits of zero
+// are not checked.
+type regexpLimits struct {
+	length       int
+	depth        int
+	alternations int
+	repeat       int
+}
+
+// check returns a RegexpLimitError if the regex value exceeds one of the
+// limits. It is checked before the regex is compiled, and returns nil if value
+// is not a valid regex so the error from compiling it can be returned instead.
+func (l regexpLimits) check(pos Position, name string, ty MatchType, value string) error {
+	exceeds := func(limit string, max, actual int) error {
+		if max <= 0 || actual <= max {
+			return nil
+		}
+		return RegexpLimitError{
+			Position: pos,
+			Matcher:  &Matcher{Type: ty, Name: name, Value: value},
+			Limit:    limit,
+			Max:      max,
+			Actual:   actual,
+		}
+	}
+	if err := exceeds("length", l.length, len(value)); err != nil {
+		return err
+	}
+	if l.depth <= 0 && l.alternations <= 0 && l.repeat <= 0 {
+		return nil
+	}
+	re, err := syntax.Parse(value, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	if err = exceeds("depth", l.depth, regexpDepth(re)); err != nil {
+		return err
+	}
+	if err = exceeds("alternations", l.alternations, countAlternations(value)); err != nil {
+		return err
+	}
+	return exceeds("repeat count", l.repeat, maxRepeat(re))
+}
+
+// regexpDepth returns the depth of the syntax tree of re.
+func regexpDepth(re *syntax.Regexp) int {
+	depth := 0
+	for _, sub := range re.Sub {
+		if d := regexpDepth(sub); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// maxRepeat returns the largest count in the repetitions in re.
+func maxRepeat(re *syntax.Regexp) int {
+	n := 0
+	if re.Op == syntax.OpRepeat {
+		// Max is -1 for repetitions without a maximum such as a{2,}
+		n = re.Min
+		if re.Max > n {
+			n = re.Max
+		}
+	}
+	for _, sub := range re.Sub {
+		if m := maxRepeat(sub); m > n {
+			n = m
+		}
+	}
+	return n
+}
+
+// countAlternations returns the number of '|' in the regex s that are not
+// escaped or in a character class. It counts the '|' in s rather than its
+// syntax tree as the parser merges alternations such as a|b into [ab].
+func countAlternations(s string) int {
+	var (
+		n       int
+		inClass bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			if strings.HasPrefix(s[i:], `\Q`) {
+				// Text is literal until \E or the end of the regex
+				end := strings.Index(s[i:], `\E`)
+				if end < 0 {
+					return n
+				}
+				i += end + 1
+			} else {
+				i++
+			}
+		case inClass:
+			if strings.HasPrefix(s[i:], "[:") {
+				// Skip named classes such as [:alpha:]
+				if end := strings.Index(s[i:], ":]"); end >= 0 {
+					i += end + 1
+				}
+			} else if s[i] == ']' {
+				inClass = false
+			}
+		case s[i] == '[':
+			inClass = true
+			// A ']' at the start of a class is a literal
+			if strings.HasPrefix(s[i+1:], "^]") {
+				i += 2
+			} else if strings.HasPrefix(s[i+1:], "]") {
+				i++
+			}
+		case s[i] == '|':
+			n++
+		}
+	}
+	return n
+}
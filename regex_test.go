@@ -0,0 +1,141 @@
+package matchers
+
+import (
+	"errors"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_InvalidRegex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		vars  map[string]string
+		error string
+		pos   Position
+	}{{
+		name:  "missing closing bracket",
+		input: "{a=~\"[a-\"}",
+		error: "1:5-1:8: [a-: invalid regex: missing closing ]",
+		pos:   Position{OffsetStart: 5, OffsetEnd: 8, LineStart: 1, LineEnd: 1, ColumnStart: 5, ColumnEnd: 8},
+	}, {
+		name:  "missing closing bracket after the same valid class",
+		input: "{foo=~\"[a-z]+x[a-z\"}",
+		error: "1:14-1:18: [a-z: invalid regex: missing closing ]",
+		pos:   Position{OffsetStart: 14, OffsetEnd: 18, LineStart: 1, LineEnd: 1, ColumnStart: 14, ColumnEnd: 18},
+	}, {
+		name:  "repetition that is invalid more than once",
+		input: "{a=~\"x**y**z\"}",
+		error: "1:6-1:8: **: invalid regex: invalid nested repetition operator",
+		pos:   Position{OffsetStart: 6, OffsetEnd: 8, LineStart: 1, LineEnd: 1, ColumnStart: 6, ColumnEnd: 8},
+	}, {
+		name:  "missing closing paren in single quotes",
+		input: "{foo!~'x(y'}",
+		error: "1:7-1:10: x(y: invalid regex: missing closing )",
+		pos:   Position{OffsetStart: 7, OffsetEnd: 10, LineStart: 1, LineEnd: 1, ColumnStart: 7, ColumnEnd: 10},
+	}, {
+		name:  "after escapes and UTF-8",
+		input: "{a=~\"é\\\\x[a-\"}",
+		error: "1:6-1:11: \\x[a: invalid regex: invalid escape sequence",
+		pos:   Position{OffsetStart: 7, OffsetEnd: 12, LineStart: 1, LineEnd: 1, ColumnStart: 6, ColumnEnd: 11},
+	}, {
+		name:  "on the next line in backticks",
+		input: "{a=~`x\n[z`}",
+		error: "2:0-2:2: [z: invalid regex: missing closing ]",
+		pos:   Position{OffsetStart: 7, OffsetEnd: 9, LineStart: 2, LineEnd: 2, ColumnStart: 0, ColumnEnd: 2},
+	}, {
+		name:  "repetition",
+		input: "{a=~\"x**\"}",
+		error: "1:6-1:8: **: invalid regex: invalid nested repetition operator",
+		pos:   Position{OffsetStart: 6, OffsetEnd: 8, LineStart: 1, LineEnd: 1, ColumnStart: 6, ColumnEnd: 8},
+	}, {
+		name:  "variable in quotes",
+		input: "{a=~\"$x(\"}",
+		vars:  map[string]string{"x": "y"},
+		error: "1:4-1:9: \"$x(\": invalid regex: missing closing )",
+		pos:   Position{OffsetStart: 4, OffsetEnd: 9, LineStart: 1, LineEnd: 1, ColumnStart: 4, ColumnEnd: 9},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var err error
+			if test.vars != nil {
+				_, err = ParseWithVariables(test.input, test.vars)
+			} else {
+				_, err = Parse(test.input)
+			}
+			require.EqualError(t, err, test.error)
+			var rerr RegexpError
+			require.True(t, errors.As(err, &rerr))
+			assert.Equal(t, test.pos, rerr.Position)
+			var serr *syntax.Error
+			assert.True(t, errors.As(err, &serr))
+		})
+	}
+}
+
+func TestParse_RegexpLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		error string
+	}{{
+		name:  "no limits",
+		input: "{a=~\"((a|b|c){1000})+\"}",
+	}, {
+		name:  "length",
+		input: "{a=~\"abcdef\",b=~abcdefgh}",
+		opts:  []Option{WithMaxRegexpLength(6)},
+		error: "1:16-1:24: b=~\"abcdefgh\": regex length of 8 exceeds the limit of 6",
+	}, {
+		name:  "depth",
+		input: "{a=~\"(foo|bar)+\"}",
+		opts:  []Option{WithMaxRegexpDepth(3)},
+		error: "1:4-1:16: a=~\"(foo|bar)+\": regex depth of 4 exceeds the limit of 3",
+	}, {
+		name:  "alternations in character classes and escaped",
+		input: "{a=~\"[|]|\\\\||b\",a!~\"a|b|c\"}",
+		opts:  []Option{WithMaxRegexpAlternations(2)},
+	}, {
+		name:  "too many alternations",
+		input: "{a!~\"a|b|c|d\"}",
+		opts:  []Option{WithMaxRegexpAlternations(2)},
+		error: "1:4-1:13: a!~\"a|b|c|d\": regex alternations of 3 exceeds the limit of 2",
+	}, {
+		name:  "repeat count",
+		input: "{a=~\"a{2,10}b{1,}\",b=~\"a{101}\"}",
+		opts:  []Option{WithMaxRegexpRepeat(100)},
+		error: "1:22-1:30: b=~\"a{101}\": regex repeat count of 101 exceeds the limit of 100",
+	}, {
+		name:  "limits are for regexes",
+		input: "{a=\"a|b|c|d\"}",
+		opts:  []Option{WithMaxRegexpLength(1), WithMaxRegexpAlternations(1)},
+	}, {
+		name:  "invalid regex",
+		input: "{a=~\"(a|b|c\"}",
+		opts:  []Option{WithMaxRegexpAlternations(1)},
+		error: "1:5-1:11: (a|b|c: invalid regex: missing closing )",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMatchers(test.input, test.opts...)
+			if test.error == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, test.error)
+		})
+	}
+
+	_, err := ParseMatchers("{foo=~\"a|b\"}", WithMaxRegexpAlternations(0), WithMaxRegexpLength(2))
+	var lerr RegexpLimitError
+	require.True(t, errors.As(err, &lerr))
+	assert.Equal(t, "length", lerr.Limit)
+	assert.Equal(t, "foo", lerr.Matcher.Name)
+	assert.Equal(t, "a|b", lerr.Matcher.Value)
+}
@@ -0,0 +1,141 @@
+package matchers
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// Record contains the matchers parsed from a record in a batch of matchers,
+// or the error if the record could not be parsed.
+type Record struct {
+	Line     int // The line number where the record starts
+	Matchers labels.Matchers
+	Err      error
+}
+
+// Scanner reads a batch of matchers from an io.Reader where each record is
+// separated with a newline or a semicolon. A record is parsed for each call
+// to Scan() which returns false once all records have been read. Records that
+// contain just whitespace are skipped. A record that cannot be parsed does
+// not stop the scanner, instead its error is returned in the Record and
+// scanning continues from the next record. The positions in errors are the
+// positions in the whole input rather than the record.
+type Scanner struct {
+	r      *bufio.Reader
+	buf    strings.Builder
+	record Record
+	err    error
+	done   bool
+	offset int // the offset of the cursor in the input
+	line   int // the line number of the cursor
+	column int // the column of the cursor in its line
+}
+
+func NewScanner(r io.Reader) *Scanner {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Scanner{
+		r:    br,
+		line: 1,
+	}
+}
+
+// Scan parses the next record in the input. It returns false when there are
+// no more records or an error occurred reading the input. A record that was
+// not read to the end because of an error is not returned.
+func (s *Scanner) Scan() bool {
+	for !s.done {
+		input, start := s.next()
+		if s.err != nil {
+			// The record is incomplete so it is not parsed
+			break
+		}
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+		p := newParserAt(input, start)
+		m, err := p.Parse()
+		s.record = Record{
+			Line:     start.LineStart,
+			Matchers: m,
+			Err:      err,
+		}
+		return true
+	}
+	s.record = Record{}
+	return false
+}
+
+// Record returns the record parsed in the last call to Scan().
+func (s *Scanner) Record() Record {
+	return s.record
+}
+
+// Err returns the first error other than io.EOF that occurred reading the
+// input. It does not return errors from parsing records.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// next returns the input of the next record and its position. Semicolons
+// inside quotes do not separate records, however newlines always do so
+// a missing end quote does not affect the records on the following lines.
+func (s *Scanner) next() (string, Position) {
+	start := Position{
+		OffsetStart: s.offset,
+		LineStart:   s.line,
+		ColumnStart: s.column,
+	}
+	s.buf.Reset()
+	var (
+		quote     rune // the quote of the text the cursor is in, if any
+		isEscaped bool
+	)
+	for {
+		r, err := readRune(s.r, &s.buf)
+		if err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			s.done = true
+			break
+		}
+		s.offset = start.OffsetStart + s.buf.Len()
+		if r == '\n' {
+			s.line++
+			s.column = 0
+		} else {
+			s.column++
+		}
+		if r == '\n' || quote == 0 && r == ';' {
+			input := s.buf.String()
+			return input[:len(input)-1], start
+		} else if isEscaped {
+			isEscaped = false
+		} else if quote != 0 && quote != '`' && r == '\\' {
+			isEscaped = true
+		} else if quote == 0 && isQuote(r) {
+			quote = r
+		} else if r == quote {
+			quote = 0
+		}
+	}
+	return s.buf.String(), start
+}
+
+// ParseMany parses each record in input, where records are separated with a
+// newline or a semicolon. It returns a Record for each record that is not
+// empty, including records that could not be parsed.
+func ParseMany(input string) []Record {
+	var records []Record
+	s := NewScanner(strings.NewReader(input))
+	for s.Scan() {
+		records = append(records, s.Record())
+	}
+	return records
+}
@@ -0,0 +1,131 @@
+package matchers
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMany(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Record
+		errs     []string
+	}{{
+		name:  "empty",
+		input: "",
+	}, {
+		name:  "blank lines",
+		input: "\n  \n\t\n",
+	}, {
+		name:  "one record",
+		input: "{foo=\"bar\"}",
+		expected: []Record{{
+			Line:     1,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar")},
+		}},
+		errs: []string{""},
+	}, {
+		name:  "records on separate lines",
+		input: "{foo=\"bar\"}\n\n{bar!=baz}\n",
+		expected: []Record{{
+			Line:     1,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar")},
+		}, {
+			Line:     3,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchNotEqual, "bar", "baz")},
+		}},
+		errs: []string{"", ""},
+	}, {
+		name:  "records separated with semicolons",
+		input: "{foo=\"bar\"};{bar!=baz};\n{baz=~\"[a-z]+\"}",
+		expected: []Record{{
+			Line:     1,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar")},
+		}, {
+			Line:     1,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchNotEqual, "bar", "baz")},
+		}, {
+			Line:     2,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "baz", "[a-z]+")},
+		}},
+		errs: []string{"", "", ""},
+	}, {
+		name:  "semicolon in quotes",
+		input: "{foo=\"bar;baz\"}",
+		expected: []Record{{
+			Line:     1,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar;baz")},
+		}},
+		errs: []string{""},
+	}, {
+		name:  "semicolon in single quotes and backticks",
+		input: "{foo='bar;baz',bar=~`;\\d`}",
+		expected: []Record{{
+			Line: 1,
+			Matchers: labels.Matchers{
+				mustNewMatcher(t, labels.MatchEqual, "foo", "bar;baz"),
+				mustNewMatcher(t, labels.MatchRegexp, "bar", ";\\d"),
+			},
+		}},
+		errs: []string{""},
+	}, {
+		name:  "errors do not stop scanning",
+		input: "{foo=\"bar\"\n{bar!=baz}\n{baz%=qux};{qux=\"quux}\n{foo=bar}",
+		expected: []Record{{
+			Line: 1,
+		}, {
+			Line:     2,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchNotEqual, "bar", "baz")},
+		}, {
+			Line: 3,
+		}, {
+			Line: 3,
+		}, {
+			Line:     4,
+			Matchers: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "bar")},
+		}},
+		errs: []string{
+			"1:0-1:10: end of input: expected close brace",
+			"",
+			"3:4-3:5: %: invalid input: expected an operator such as '=', '!=', '=~' or '!~'",
+			"3:16-3:22: \"quux}: missing end \": expected label value",
+			"",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records := ParseMany(test.input)
+			require.Len(t, records, len(test.expected))
+			for i, record := range records {
+				assert.Equal(t, test.expected[i].Line, record.Line)
+				if test.errs[i] != "" {
+					assert.EqualError(t, record.Err, test.errs[i])
+				} else {
+					require.NoError(t, record.Err)
+					assert.EqualValues(t, test.expected[i].Matchers, record.Matchers)
+				}
+			}
+		})
+	}
+}
+
+func TestScanner_Err(t *testing.T) {
+	readErr := errors.New("read error")
+	r := io.MultiReader(strings.NewReader("{foo=bar}\n{bar=baz"), iotest.ErrReader(readErr))
+	s := NewScanner(r)
+	require.True(t, s.Scan())
+	require.NoError(t, s.Record().Err)
+	// The second record is not returned as the read error stops it partway
+	require.False(t, s.Scan())
+	require.Equal(t, readErr, s.Err())
+	require.Equal(t, Record{}, s.Record())
+}
@@ -0,0 +1,72 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	ErrInvalidLabelName = errors.New("label name cannot be written without quotes")
+	ErrEmptySelector    = errors.New("selector must have a matcher that does not match the empty string")
+)
+
+// promQLKeywords are the keywords in PromQL that cannot be metric names
+// before an open brace.
+var promQLKeywords = map[string]bool{
+	"and": true, "or": true, "unless": true, "atan2": true,
+	"avg": true, "bottomk": true, "count": true, "count_values": true,
+	"group": true, "limitk": true, "limit_ratio": true, "max": true,
+	"min": true, "quantile": true, "stddev": true, "stdvar": true,
+	"sum": true, "topk": true, "bool": true, "by": true, "end": true,
+	"group_left": true, "group_right": true, "ignoring": true, "inf": true,
+	"nan": true, "offset": true, "on": true, "start": true, "without": true,
+}
+
+// PromQL returns the matchers as a PromQL series selector such as
+// http_requests_total{job="api"}. The value of an equality matcher for
+// __name__ is written as the metric name if it is not a PromQL keyword. It
+// returns an error if a label name can only be written in quotes, or if all
+// the matchers match the empty string as PromQL does not accept such
+// selectors.
+func PromQL(matchers labels.Matchers) (string, error) {
+	if err := checkSelector(matchers); err != nil {
+		return "", err
+	}
+	return formatSelector(matchers, func(s string) bool {
+		return isIdent(s) && !promQLKeywords[strings.ToLower(s)]
+	}), nil
+}
+
+// LogQL returns the matchers as a LogQL stream selector such as
+// {job="api"}. It returns an error if a label name can only be written in
+// quotes, or if all the matchers match the empty string as LogQL does not
+// accept such selectors.
+func LogQL(matchers labels.Matchers) (string, error) {
+	if err := checkSelector(matchers); err != nil {
+		return "", err
+	}
+	return Format(matchers), nil
+}
+
+// checkSelector returns an error if the matchers cannot be written as a
+// PromQL or LogQL selector. Label values are written with the same escapes in
+// both, so just the label names and empty selectors need to be checked.
+func checkSelector(matchers labels.Matchers) error {
+	hasNonEmpty := false
+	for _, m := range matchers {
+		if !model.LabelName(m.Name).IsValid() {
+			return fmt.Errorf("%s: %w", formatMatcher(m), ErrInvalidLabelName)
+		}
+		if !m.Matches("") {
+			hasNonEmpty = true
+		}
+	}
+	if !hasNonEmpty {
+		return fmt.Errorf("%s: %w", Format(matchers), ErrEmptySelector)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		error    string
+	}{{
+		name:     "matchers",
+		input:    "{job=\"api\",instance=~\"a|b\"}",
+		expected: "{job=\"api\", instance=~\"a|b\"}",
+	}, {
+		name:     "metric name",
+		input:    "{job=\"api\",__name__=\"http_requests_total\"}",
+		expected: "http_requests_total{job=\"api\"}",
+	}, {
+		name:     "metric name with colons",
+		input:    "{__name__=\"job:http_requests:rate5m\"}",
+		expected: "job:http_requests:rate5m",
+	}, {
+		name:     "metric name that is a keyword",
+		input:    "{__name__=\"sum\",job=\"api\"}",
+		expected: "{__name__=\"sum\", job=\"api\"}",
+	}, {
+		name:     "escaped values",
+		input:    "{job=\"a\\\"b\\\\c\\n\",path=~\"/api/\\\\d+\"}",
+		expected: "{job=\"a\\\"b\\\\c\\n\", path=~\"/api/\\\\d+\"}",
+	}, {
+		name:  "label name that needs quotes",
+		input: "{\"foo.bar\"=\"baz\"}",
+		error: "\"foo.bar\"=\"baz\": label name cannot be written without quotes",
+	}, {
+		name:  "label name with colon",
+		input: "{foo:bar=\"baz\"}",
+		error: "foo:bar=\"baz\": label name cannot be written without quotes",
+	}, {
+		name:  "no matchers",
+		input: "{}",
+		error: "{}: selector must have a matcher that does not match the empty string",
+	}, {
+		name:  "matchers that match the empty string",
+		input: "{job=\"\",instance=~\".*\",env!=\"prod\"}",
+		error: "{job=\"\", instance=~\".*\", env!=\"prod\"}: selector must have a matcher that does not match the empty string",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms, err := Parse(test.input)
+			require.NoError(t, err)
+			s, err := PromQL(ms)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, s)
+			}
+		})
+	}
+}
+
+func TestLogQL(t *testing.T) {
+	ms, err := Parse("{__name__=\"http_requests_total\",job=\"api\",namespace^=\"prod-\"}")
+	require.NoError(t, err)
+	s, err := LogQL(ms)
+	require.NoError(t, err)
+	assert.Equal(t, "{__name__=\"http_requests_total\", job=\"api\", namespace=~\"prod-(?s:.*)\"}", s)
+
+	ms, err = Parse("{\"service.name\"=\"api\"}")
+	require.NoError(t, err)
+	_, err = LogQL(ms)
+	require.EqualError(t, err, "\"service.name\"=\"api\": label name cannot be written without quotes")
+
+	ms, err = Parse("{job!=\"api\"}")
+	require.NoError(t, err)
+	_, err = LogQL(ms)
+	require.EqualError(t, err, "{job!=\"api\"}: selector must have a matcher that does not match the empty string")
+}
@@ -0,0 +1,199 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrUnsupportedLabelName = errors.New("label name is not supported")
+)
+
+// Dialect writes the parts of a SQL condition that differ between databases.
+type Dialect interface {
+	// Placeholder returns the placeholder for the nth argument, starting
+	// from 1.
+	Placeholder(n int) string
+
+	// Label returns an expression for the value of a label in the JSON
+	// object in column, or NULL if the label is not in the object. arg is
+	// the placeholder for the argument returned from LabelArg.
+	Label(column, arg string) string
+
+	// LabelArg returns the argument for the label name in Label. It returns
+	// an error wrapping ErrUnsupportedLabelName if the label name cannot be
+	// written in the dialect.
+	LabelArg(name string) (any, error)
+
+	// Regexp returns a condition that is true if the text in expr matches
+	// the regex in the placeholder arg. The regex is anchored at both ends.
+	Regexp(expr, arg string) string
+}
+
+// PostgreSQL is the Dialect for PostgreSQL where labels are in a JSONB
+// column. Regexes are POSIX regular expressions which have mostly the same
+// syntax as Go regexes.
+var PostgreSQL Dialect = postgreSQLDialect{}
+
+type postgreSQLDialect struct{}
+
+func (postgreSQLDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgreSQLDialect) Label(column, arg string) string {
+	return column + "->>" + arg
+}
+
+func (postgreSQLDialect) LabelArg(name string) (any, error) {
+	return name, nil
+}
+
+func (postgreSQLDialect) Regexp(expr, arg string) string {
+	return expr + " ~ " + arg
+}
+
+// SQLite is the Dialect for SQLite where labels are in a JSON text column.
+// SQLite does not have a regexp function, so one must be added for the
+// REGEXP operator, such as with RegisterDeterministicScalarFunction in
+// modernc.org/sqlite. Label names that contain '"' are not supported as they
+// cannot be quoted in JSON paths.
+var SQLite Dialect = sqliteDialect{}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (sqliteDialect) Label(column, arg string) string {
+	return column + "->>" + arg
+}
+
+func (sqliteDialect) LabelArg(name string) (any, error) {
+	if strings.Contains(name, "\"") {
+		return nil, fmt.Errorf("%w in SQLite as it contains '\"'", ErrUnsupportedLabelName)
+	}
+	return "$.\"" + name + "\"", nil
+}
+
+func (sqliteDialect) Regexp(expr, arg string) string {
+	return expr + " REGEXP " + arg
+}
+
+// SQL returns the matchers as a SQL condition for the labels in the JSON
+// column and the arguments for its placeholders. Like Alertmanager, a label
+// that is not in the JSON object has the empty value except in exists
+// matchers. Prefix, suffix, contains and case-insensitive matchers are written
+// as regexes. column is written as it is in the condition, so it must not come
+// from user input. It returns an error if a matcher is a comparison or its
+// label name cannot be written in the dialect.
+func SQL(ms Matchers, column string, d Dialect) (string, []any, error) {
+	w := sqlWriter{column: column, dialect: d}
+	cond, err := w.matchers(ms)
+	if err != nil {
+		return "", nil, err
+	}
+	return cond, w.args, nil
+}
+
+// sqlWriter writes the SQL conditions for matchers and collects the
+// arguments for their placeholders.
+type sqlWriter struct {
+	args    []any
+	column  string
+	dialect Dialect
+}
+
+// arg adds v to the arguments and returns its placeholder.
+func (w *sqlWriter) arg(v any) string {
+	w.args = append(w.args, v)
+	return w.dialect.Placeholder(len(w.args))
+}
+
+func (w *sqlWriter) matchers(ms Matchers) (string, error) {
+	if len(ms) == 0 {
+		return "TRUE", nil
+	}
+	conds := make([]string, 0, len(ms))
+	for _, m := range ms {
+		cond, err := w.matcher(m)
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, cond)
+	}
+	return strings.Join(conds, " AND "), nil
+}
+
+func (w *sqlWriter) matcher(m *Matcher) (string, error) {
+	switch m.Type {
+	case MatchNot:
+		cond, err := w.matchers(m.Group)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + cond + ")", nil
+	case MatchLess, MatchLessEqual, MatchGreater, MatchGreaterEqual:
+		return "", fmt.Errorf("%s: %w", m, ErrUnsupportedMatchType)
+	}
+	label, err := w.label(m.Name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", m, err)
+	}
+	switch m.Type {
+	case MatchExists:
+		return "(" + label + " IS NOT NULL)", nil
+	case MatchNotExists:
+		return "(" + label + " IS NULL)", nil
+	}
+	lm, err := m.Labels()
+	if err != nil {
+		return "", err
+	}
+	re := lm.Value
+	switch m.Type {
+	case MatchPrefix, MatchNotPrefix, MatchSuffix, MatchNotSuffix, MatchContains, MatchNotContains:
+		// The value is quoted so the only (?s:.*) are the ones from Labels
+		re = strings.ReplaceAll(re, anyValue, sqlAnyValue)
+	}
+	// A label that is not in the JSON object has the empty value
+	value := "COALESCE(" + label + ", '')"
+	switch lm.Type {
+	case labels.MatchEqual:
+		return "(" + value + " = " + w.arg(lm.Value) + ")", nil
+	case labels.MatchNotEqual:
+		return "(" + value + " <> " + w.arg(lm.Value) + ")", nil
+	case labels.MatchRegexp:
+		return "(" + w.dialect.Regexp(value, w.arg(anchor(re))) + ")", nil
+	default:
+		return "NOT (" + w.dialect.Regexp(value, w.arg(anchor(re))) + ")", nil
+	}
+}
+
+// sqlAnyValue is a regex that matches any value, including values with
+// newlines, in both Go and PostgreSQL regexes. It is used instead of anyValue
+// as PostgreSQL does not accept flags in groups.
+const sqlAnyValue = `(?:.|\n)*`
+
+// anchor returns the regex re anchored at both ends. A (?i) flag at the start
+// of re is kept at the start as PostgreSQL does not accept flags in groups.
+func anchor(re string) string {
+	if strings.HasPrefix(re, "(?i)") {
+		return "(?i)^(?:" + re[len("(?i)"):] + ")$"
+	}
+	return "^(?:" + re + ")$"
+}
+
+// label returns the expression for the value of the label name.
+func (w *sqlWriter) label(name string) (string, error) {
+	arg, err := w.dialect.LabelArg(name)
+	if err != nil {
+		return "", err
+	}
+	return w.dialect.Label(w.column, w.arg(arg)), nil
+}
@@ -0,0 +1,135 @@
+package matchers
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"modernc.org/sqlite"
+)
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		re, err := regexp.Compile(args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(args[1].(string)), nil
+	})
+}
+
+func TestSQL_PostgreSQL(t *testing.T) {
+	ms, err := ParseMatchers("{env=prod,team!~\"core|db\",service^=api,exists(cluster),not {severity~=info}}")
+	require.NoError(t, err)
+	cond, args, err := SQL(ms, "labels", PostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, "(COALESCE(labels->>$1, '') = $2)"+
+		" AND NOT (COALESCE(labels->>$3, '') ~ $4)"+
+		" AND (COALESCE(labels->>$5, '') ~ $6)"+
+		" AND (labels->>$7 IS NOT NULL)"+
+		" AND NOT ((COALESCE(labels->>$8, '') ~ $9))", cond)
+	assert.Equal(t, []any{
+		"env", "prod",
+		"team", "^(?:core|db)$",
+		"service", "^(?:api(?:.|\\n)*)$",
+		"cluster",
+		"severity", "(?i)^(?:info)$",
+	}, args)
+
+	_, _, err = SQL(Matchers{mustNewMatcherOfType(t, MatchGreater, "priority", "1")}, "labels", PostgreSQL)
+	require.EqualError(t, err, "priority>\"1\": match type is not supported in Alertmanager")
+}
+
+func TestSQL_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec("CREATE TABLE alerts (id INTEGER PRIMARY KEY, labels TEXT)")
+	require.NoError(t, err)
+	lsets := []model.LabelSet{
+		{"env": "prod", "team": "core", "severity": "critical"},
+		{"env": "prod", "team": "db", "severity": "warning"},
+		{"env": "prod", "severity": "critical", "cluster": ""},
+		{"env": "dev", "team": "core", "service": "api-payments"},
+		{"env": "Prod", "team": "web", "a.b": "c"},
+		{},
+		{"summary": "disk full\non /var"},
+	}
+	for i, lset := range lsets {
+		b, err := json.Marshal(lset)
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO alerts (id, labels) VALUES (?, ?)", i, string(b))
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{input: "{}", expected: []int{0, 1, 2, 3, 4, 5, 6}},
+		{input: "{env=prod}", expected: []int{0, 1, 2}},
+		{input: "{env=prod,not {team=core,severity=critical}}", expected: []int{1, 2}},
+		{input: "{team=\"\"}", expected: []int{2, 5, 6}},
+		{input: "{team!=core}", expected: []int{1, 2, 4, 5, 6}},
+		{input: "{team=~\"core|db\"}", expected: []int{0, 1, 3}},
+		{input: "{team!~\"c.*\"}", expected: []int{1, 2, 4, 5, 6}},
+		{input: "{service^=api,service$=payments,service*=\"-pay\"}", expected: []int{3}},
+		{input: "{env~=PROD}", expected: []int{0, 1, 2, 4}},
+		{input: "{exists(cluster)}", expected: []int{2}},
+		{input: "{!exists(team),!exists(cluster)}", expected: []int{5, 6}},
+		{input: "{summary^=disk,summary$=\"/var\",summary*=\"full\\non\"}", expected: []int{6}},
+		{input: "{summary!^=disk}", expected: []int{0, 1, 2, 3, 4, 5}},
+		{input: "{\"a.b\"=c}", expected: []int{4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			ms, err := ParseMatchers(test.input)
+			require.NoError(t, err)
+			cond, args, err := SQL(ms, "labels", SQLite)
+			require.NoError(t, err)
+			rows, err := db.Query(fmt.Sprintf("SELECT id FROM alerts WHERE %s ORDER BY id", cond), args...)
+			require.NoError(t, err)
+			defer rows.Close()
+			var ids []int
+			for rows.Next() {
+				var id int
+				require.NoError(t, rows.Scan(&id))
+				ids = append(ids, id)
+			}
+			require.NoError(t, rows.Err())
+			assert.Equal(t, test.expected, ids)
+			// The query must match the same label sets as the matchers
+			var expected []int
+			for i, lset := range lsets {
+				if ms.Matches(lset) {
+					expected = append(expected, i)
+				}
+			}
+			assert.Equal(t, expected, ids)
+		})
+	}
+}
+
+func TestSQL_UnsupportedLabelName(t *testing.T) {
+	ms, err := ParseMatchers("{\"x\\\"y\"=\"1\"}")
+	require.NoError(t, err)
+	_, _, err = SQL(ms, "labels", SQLite)
+	require.ErrorIs(t, err, ErrUnsupportedLabelName)
+	require.EqualError(t, err, "\"x\\\"y\"=\"1\": label name is not supported in SQLite as it contains '\"'")
+	ms, err = ParseMatchers("{not {exists(\"x\\\"y\")}}")
+	require.NoError(t, err)
+	_, _, err = SQL(ms, "labels", SQLite)
+	require.ErrorIs(t, err, ErrUnsupportedLabelName)
+	// PostgreSQL does not use paths so the label name is supported
+	cond, args, err := SQL(ms, "labels", PostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, "NOT ((labels->>$1 IS NOT NULL))", cond)
+	assert.Equal(t, []any{"x\"y"}, args)
+}
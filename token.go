@@ -9,27 +9,45 @@ type TokenKind int
 const (
 	TokenNone TokenKind = iota
 	TokenCloseBrace
+	TokenCloseParen
 	TokenComma
 	TokenIdent
+	TokenNot
+	TokenNumber
 	TokenOpenBrace
+	TokenOpenParen
 	TokenOperator
 	TokenQuoted
+	TokenReference
+	TokenVariable
 )
 
 func (k TokenKind) String() string {
 	switch k {
 	case TokenCloseBrace:
 		return "CloseBrace"
+	case TokenCloseParen:
+		return "CloseParen"
 	case TokenComma:
 		return "Comma"
 	case TokenIdent:
 		return "Ident"
+	case TokenNot:
+		return "Not"
+	case TokenNumber:
+		return "Number"
 	case TokenOpenBrace:
 		return "OpenBrace"
+	case TokenOpenParen:
+		return "OpenParen"
 	case TokenOperator:
 		return "Op"
 	case TokenQuoted:
 		return "Quoted"
+	case TokenReference:
+		return "Reference"
+	case TokenVariable:
+		return "Variable"
 	default:
 		return "None"
 	}
@@ -52,6 +70,12 @@ func IsNone(t Token) bool {
 type Position struct {
 	OffsetStart int // The start position in the input
 	OffsetEnd   int // The end position in the input
-	ColumnStart int // The column number
-	ColumnEnd   int // The end of the column
+	LineStart   int // The line number, starting from 1
+	LineEnd     int // The line number of the end position
+	ColumnStart int // The column number in the line, starting from 0
+	ColumnEnd   int // The column number of the end position in its line
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d-%d:%d", p.LineStart, p.ColumnStart, p.LineEnd, p.ColumnEnd)
 }
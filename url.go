@@ -0,0 +1,47 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrNotSingleMatcher = errors.New("expected a single matcher")
+)
+
+// filterParam is the query parameter for matchers in Alertmanager and Grafana
+// links.
+const filterParam = "filter"
+
+// ToURLValues returns the matchers as query parameters with a filter
+// parameter for each matcher, such as filter=env%3D%22prod%22. The matchers
+// are written as in Format without the braces, and are escaped when the
+// values are encoded with Encode.
+func ToURLValues(matchers labels.Matchers) url.Values {
+	v := make(url.Values)
+	for _, m := range matchers {
+		v.Add(filterParam, formatMatcher(m))
+	}
+	return v
+}
+
+// FromURLValues parses the matcher in each filter parameter with Parse and
+// returns the matchers in the order of the parameters. It returns an error if
+// a parameter cannot be parsed or does not have exactly one matcher.
+func FromURLValues(v url.Values, opts ...Option) (labels.Matchers, error) {
+	var result labels.Matchers
+	for _, s := range v[filterParam] {
+		ms, err := Parse(s, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("filter %q: %w", s, err)
+		}
+		if len(ms) != 1 {
+			return nil, fmt.Errorf("filter %q: %w", s, ErrNotSingleMatcher)
+		}
+		result = append(result, ms[0])
+	}
+	return result, nil
+}
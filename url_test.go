@@ -0,0 +1,109 @@
+package matchers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    labels.Matchers
+		expected string
+	}{{
+		name: "all match types",
+		input: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "a", "1"),
+			mustNewMatcher(t, labels.MatchNotEqual, "b", "2"),
+			mustNewMatcher(t, labels.MatchRegexp, "c", "3|4"),
+			mustNewMatcher(t, labels.MatchNotRegexp, "d", "5"),
+		},
+		expected: "filter=a%3D%221%22&filter=b%21%3D%222%22&filter=c%3D~%223%7C4%22&filter=d%21~%225%22",
+	}, {
+		name: "reserved characters",
+		input: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "query", "a=1&b=2#top"),
+			mustNewMatcher(t, labels.MatchEqual, "path", "/api?x=%20+y"),
+		},
+		expected: "filter=query%3D%22a%3D1%26b%3D2%23top%22&filter=path%3D%22%2Fapi%3Fx%3D%2520%2By%22",
+	}, {
+		name: "spaces, quotes and newlines",
+		input: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "summary", "say \"hi\"\nbye"),
+		},
+		expected: "filter=summary%3D%22say+%5C%22hi%5C%22%5Cnbye%22",
+	}, {
+		name: "label names that are not idents",
+		input: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "foo bar", "🙂"),
+		},
+		expected: "filter=%22foo+bar%22%3D%22%F0%9F%99%82%22",
+	}, {
+		name:     "no matchers",
+		input:    labels.Matchers{},
+		expected: "",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := ToURLValues(test.input).Encode()
+			assert.Equal(t, test.expected, s)
+			// The matchers must be the same after decoding the query
+			v, err := url.ParseQuery(s)
+			require.NoError(t, err)
+			result, err := FromURLValues(v)
+			require.NoError(t, err)
+			if len(test.input) == 0 {
+				assert.Empty(t, result)
+			} else {
+				assert.Equal(t, test.input, result)
+			}
+		})
+	}
+}
+
+func TestFromURLValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected labels.Matchers
+		error    string
+	}{{
+		name:  "matchers with and without quotes",
+		input: "filter=env%3Dprod&filter=%7Bteam%3D~%22a.*%22%7D&other=1",
+		expected: labels.Matchers{
+			mustNewMatcher(t, labels.MatchEqual, "env", "prod"),
+			mustNewMatcher(t, labels.MatchRegexp, "team", "a.*"),
+		},
+	}, {
+		name:  "more than one matcher",
+		input: "filter=a%3D1%2Cb%3D2",
+		error: "filter \"a=1,b=2\": expected a single matcher",
+	}, {
+		name:  "no matchers",
+		input: "filter=",
+		error: "filter \"\": expected a single matcher",
+	}, {
+		name:  "invalid matcher",
+		input: "filter=a%3D",
+		error: "filter \"a=\": 1:0-1:2: end of input: expected label value",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := url.ParseQuery(test.input)
+			require.NoError(t, err)
+			result, err := FromURLValues(v)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, result)
+			}
+		})
+	}
+}
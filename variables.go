@@ -0,0 +1,168 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+var (
+	ErrUndefinedVariable = errors.New("undefined variable")
+)
+
+// ParseWithVariables returns a series of matchers or an error like Parse,
+// but label values can contain variables such as $cluster or ${cluster}.
+// A variable can be used in place of a label value, or inside a label value
+// in quotes, and is replaced with its value from vars. If the matcher is a
+// regex then the value is escaped so it matches just the value. In quotes
+// "$$" is a literal '$', as is a '$' that is not followed by a name.
+// It returns an error if a variable is not in vars.
+func ParseWithVariables(input string, vars map[string]string, opts ...Option) (labels.Matchers, error) {
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	p := NewParser(input, opts...)
+	p.vars = vars
+	return p.Parse()
+}
+
+// expandVariable returns the value of the variable in tok.
+func (p *Parser) expandVariable(tok Token, isRegex bool) (string, error) {
+	name := strings.TrimSuffix(strings.TrimPrefix(tok.Value[1:], "{"), "}")
+	return p.lookupVariable(name, tok.Value, tok.Position, isRegex)
+}
+
+// lookupVariable returns the value of the variable name, escaped if isRegex
+// is true. text and pos are the text and position of the variable in the input
+// and are used in the error if the variable is undefined.
+func (p *Parser) lookupVariable(name, text string, pos Position, isRegex bool) (string, error) {
+	value, ok := p.vars[name]
+	if !ok {
+		return "", fmt.Errorf("%s: %s: %w", pos, text, ErrUndefinedVariable)
+	}
+	if isRegex {
+		value = regexp.QuoteMeta(value)
+	}
+	return value, nil
+}
+
+// interpolate returns the unquoted value of tok with each variable replaced
+// with its value.
+func (p *Parser) interpolate(tok Token, isRegex bool) (string, error) {
+	var (
+		b     strings.Builder
+		quote = tok.Value[:1]
+		raw   = tok.Value[1 : len(tok.Value)-1]
+		start = 0 // the start of the text that has not been written to b
+		pos   = Position{
+			OffsetStart: tok.OffsetStart + 1,
+			LineStart:   tok.LineStart,
+			ColumnStart: tok.ColumnStart + 1,
+		}
+	)
+	// write writes the text in raw from start to end to b
+	write := func(end int) error {
+		s, err := unquote(quote + raw[start:end] + quote)
+		if err != nil {
+			return fmt.Errorf("%s: %s: invalid input", tok.Position, tok.Value)
+		}
+		b.WriteString(s)
+		return nil
+	}
+	// advance moves pos over the text in raw from i to j
+	advance := func(i, j int) {
+		for _, r := range raw[i:j] {
+			if r == '\n' {
+				pos.LineStart++
+				pos.ColumnStart = 0
+			} else {
+				pos.ColumnStart++
+			}
+		}
+		pos.OffsetStart = tok.OffsetStart + 1 + j
+	}
+	for i := 0; i < len(raw); {
+		if raw[i] == '\\' && quote != "`" {
+			// Skip the escaped rune as it cannot start a variable
+			_, width := utf8.DecodeRuneInString(raw[i+1:])
+			advance(i, i+1+width)
+			i += 1 + width
+			continue
+		} else if raw[i] != '$' {
+			_, width := utf8.DecodeRuneInString(raw[i:])
+			advance(i, i+width)
+			i += width
+			continue
+		}
+		if strings.HasPrefix(raw[i:], "$$") {
+			if err := write(i + 1); err != nil {
+				return "", err
+			}
+			advance(i, i+2)
+			i += 2
+			start = i
+			continue
+		}
+		name, n := scanVariableName(raw[i:])
+		if n == 0 {
+			// A '$' that is not followed by a name, such as the end of a
+			// regex, is a literal '$'
+			advance(i, i+1)
+			i++
+			continue
+		}
+		if err := write(i); err != nil {
+			return "", err
+		}
+		varPos := pos
+		varPos.OffsetEnd = pos.OffsetStart + n
+		varPos.LineEnd = pos.LineStart
+		varPos.ColumnEnd = pos.ColumnStart + n
+		value, err := p.lookupVariable(name, raw[i:i+n], varPos, isRegex)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		advance(i, i+n)
+		i += n
+		start = i
+	}
+	if err := write(len(raw)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// scanVariableName returns the name of the variable at the start of s and the
+// length of the variable, including the '$' and braces. It returns 0 if s does
+// not start with a variable.
+func scanVariableName(s string) (string, int) {
+	i := 1
+	hasBrace := strings.HasPrefix(s[i:], "{")
+	if hasBrace {
+		i++
+	}
+	j := i
+	for j < len(s) {
+		r := rune(s[j])
+		if !isAlpha(r) && r != '_' && (!isNum(r) || j == i) {
+			break
+		}
+		j++
+	}
+	if j == i {
+		return "", 0
+	}
+	name := s[i:j]
+	if hasBrace {
+		if !strings.HasPrefix(s[j:], "}") {
+			return "", 0
+		}
+		j++
+	}
+	return name, j
+}
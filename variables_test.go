@@ -0,0 +1,97 @@
+package matchers
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithVariables(t *testing.T) {
+	vars := map[string]string{
+		"cluster":   "prod-1",
+		"namespace": "payments",
+		"regex":     "a.b",
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected labels.Matchers
+		error    string
+	}{{
+		name:     "variable",
+		input:    "{cluster=$cluster}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "cluster", "prod-1")},
+	}, {
+		name:     "variable with braces",
+		input:    "{cluster!=${cluster}}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchNotEqual, "cluster", "prod-1")},
+	}, {
+		name:     "variable in quotes",
+		input:    "{namespace=\"$namespace-${cluster}\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "namespace", "payments-prod-1")},
+	}, {
+		name:     "variable in quotes with escape sequences",
+		input:    "{namespace=\"\\\"$namespace\\\"\\n\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "namespace", "\"payments\"\n")},
+	}, {
+		name:     "variable in regex is escaped",
+		input:    "{foo=~$regex,bar!~\"^$regex|[a-z]+$\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "foo", "a\\.b"), mustNewMatcher(t, labels.MatchNotRegexp, "bar", "^a\\.b|[a-z]+$")},
+	}, {
+		name:     "literal dollar",
+		input:    "{foo=\"$$cluster costs $5\"}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "$cluster costs $5")},
+	}, {
+		name:     "variable in single quotes",
+		input:    "{namespace='\\'$namespace\\''}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "namespace", "'payments'")},
+	}, {
+		name:     "variable in backticks",
+		input:    "{namespace=~`\\d+-$namespace`}",
+		expected: labels.Matchers{mustNewMatcher(t, labels.MatchRegexp, "namespace", "\\d+-payments")},
+	}, {
+		name:  "undefined variable",
+		input: "{cluster=$cluster,env=$env}",
+		error: "1:22-1:26: $env: undefined variable",
+	}, {
+		name:  "undefined variable in quotes",
+		input: "{cluster=$cluster,\nenv=\"env-${env}\"}",
+		error: "2:9-2:15: ${env}: undefined variable",
+	}, {
+		name:  "variable as label name",
+		input: "{$cluster=foo}",
+		error: "1:1-1:9: unexpected $cluster: expected label name",
+	}, {
+		name:  "unterminated variable",
+		input: "{cluster=${cluster",
+		error: "1:9-1:18: unexpected end of input, expected one of '}': expected label value",
+	}, {
+		name:  "invalid escape sequence",
+		input: "{foo=\"$cluster\\w\"}",
+		error: "1:5-1:17: \"$cluster\\w\": invalid input",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matchers, err := ParseWithVariables(test.input, vars)
+			if test.error != "" {
+				require.EqualError(t, err, test.error)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, test.expected, matchers)
+			}
+		})
+	}
+}
+
+func TestParse_Variable(t *testing.T) {
+	// Variables are not replaced in quotes
+	matchers, err := Parse("{foo=\"$cluster\"}")
+	require.NoError(t, err)
+	assert.EqualValues(t, labels.Matchers{mustNewMatcher(t, labels.MatchEqual, "foo", "$cluster")}, matchers)
+	// and are not accepted as label values
+	_, err = Parse("{foo=$cluster}")
+	require.EqualError(t, err, "1:5-1:13: unexpected $cluster: expected label value")
+}
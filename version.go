@@ -0,0 +1,93 @@
+package matchers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a semantic version such as 1.2.3 or 1.0.0-rc.1.
+type version struct {
+	major, minor, patch uint64
+	pre                 []string
+}
+
+// parseVersion parses s as a semantic version. It is more lenient than the
+// semver specification as it accepts a leading 'v' and versions with just a
+// major or major and minor version, such as v1 and 1.2, where the missing
+// numbers are 0. Build metadata is ignored.
+func parseVersion(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.pre = strings.Split(s[i+1:], ".")
+		for _, id := range v.pre {
+			if id == "" {
+				return version{}, false
+			}
+		}
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	nums := []*uint64{&v.major, &v.minor, &v.patch}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return version{}, false
+		}
+		*nums[i] = n
+	}
+	return v, true
+}
+
+// compare returns -1 if v has lower precedence than w, 1 if v has higher
+// precedence than w, and 0 if both have the same precedence.
+func (v version) compare(w version) int {
+	if c := compareUint(v.major, w.major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.minor, w.minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.patch, w.patch); c != 0 {
+		return c
+	}
+	// A version without a pre-release has higher precedence than one with
+	if len(v.pre) == 0 || len(w.pre) == 0 {
+		return -compareUint(uint64(len(v.pre)), uint64(len(w.pre)))
+	}
+	for i := 0; i < len(v.pre) && i < len(w.pre); i++ {
+		a, aErr := strconv.ParseUint(v.pre[i], 10, 64)
+		b, bErr := strconv.ParseUint(w.pre[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareUint(a, b); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			// Numeric identifiers have lower precedence than alphanumeric
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(v.pre[i], w.pre[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareUint(uint64(len(v.pre)), uint64(len(w.pre)))
+}
+
+func compareUint(a, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}